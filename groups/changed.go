@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// groupsAtRevision returns the groups defined by the groups.yaml files under
+// dir, which must be in a git work tree, at the git revision rev.
+//
+// The files are only parsed: they were validated when they were committed,
+// against what may be an older schema.
+func groupsAtRevision(dir, rev string) ([]GoogleGroup, error) {
+	out, err := git(dir, "ls-tree", "-r", "-z", "--name-only", rev, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	var groups []GoogleGroup
+	for _, path := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		if filepath.Base(path) != "groups.yaml" {
+			continue
+		}
+		content, err := git(dir, "show", rev+":./"+path)
+		if err != nil {
+			return nil, err
+		}
+		var gc GroupsConfig
+		if err := yaml.Unmarshal(content, &gc); err != nil {
+			return nil, fmt.Errorf("error parsing groups config at %s:%s: %w", rev, path, err)
+		}
+		groups = append(groups, gc.Groups...)
+	}
+	return groups, nil
+}
+
+// git runs git with args in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// changedGroups returns the groups of current that are not defined the same
+// way in previous, ignoring the case and order of addresses but not their
+// schedule, and the emails of the groups of previous that are no longer in
+// current. Groups of previous whose email id is a previous email id of a
+// group of current were renamed, not removed.
+func changedGroups(previous, current []GoogleGroup) (changed []GoogleGroup, removed []string) {
+	before := map[string]GoogleGroup{}
+	for _, g := range previous {
+		before[CanonicalEmail(g.EmailId)] = g
+	}
+	for _, g := range current {
+		key := CanonicalEmail(g.EmailId)
+		prev, ok := before[key]
+		delete(before, key)
+		for _, id := range g.PreviousEmailIds {
+			if _, renamed := before[CanonicalEmail(id)]; renamed {
+				delete(before, CanonicalEmail(id))
+				ok = false
+			}
+		}
+		if ok && reflect.DeepEqual(comparableGroup(prev), comparableGroup(g)) && reflect.DeepEqual(prev.Schedule, g.Schedule) {
+			continue
+		}
+		changed = append(changed, g)
+	}
+	for _, g := range before {
+		removed = append(removed, g.EmailId)
+	}
+	sort.Strings(removed)
+	return changed, removed
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangedGroups(t *testing.T) {
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := from.Add(24 * time.Hour)
+	previous := []GoogleGroup{
+		{EmailId: "unchanged@example.com", Members: []string{"a@example.com", "b@example.com"}},
+		{EmailId: "reordered@example.com", Members: []string{"a@example.com", "B@example.com"}},
+		{EmailId: "changed@example.com", Members: []string{"a@example.com"}},
+		{EmailId: "settings@example.com", Settings: map[string]string{"ReconcileMembers": "false"}},
+		{EmailId: "removed@example.com"},
+		{EmailId: "renamed@example.com", Members: []string{"a@example.com"}},
+		{EmailId: "scheduled@example.com", Members: []string{"a@example.com"}, Schedule: map[string]MemberSchedule{"a@example.com": {From: &from}}},
+	}
+	current := []GoogleGroup{
+		{EmailId: "unchanged@example.com", Members: []string{"a@example.com", "b@example.com"}},
+		{EmailId: "reordered@example.com", Members: []string{"b@example.com", "a@example.com"}},
+		{EmailId: "changed@example.com", Members: []string{"a@example.com", "c@example.com"}},
+		{EmailId: "settings@example.com", Settings: map[string]string{"ReconcileMembers": "true"}},
+		{EmailId: "added@example.com"},
+		{EmailId: "new-name@example.com", PreviousEmailIds: []string{"Renamed@example.com"}, Members: []string{"a@example.com"}},
+		{EmailId: "scheduled@example.com", Members: []string{"a@example.com"}, Schedule: map[string]MemberSchedule{"a@example.com": {From: &later}}},
+	}
+
+	changed, removed := changedGroups(previous, current)
+	var actual []string
+	for _, g := range changed {
+		actual = append(actual, g.EmailId)
+	}
+	expected := []string{"changed@example.com", "settings@example.com", "added@example.com", "new-name@example.com", "scheduled@example.com"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected changed groups %v, got %v", expected, actual)
+	}
+	if expected := []string{"removed@example.com"}; !reflect.DeepEqual(expected, removed) {
+		t.Errorf("expected removed groups %v, got %v", expected, removed)
+	}
+}
+
+func TestGroupsAtRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	repo := t.TempDir()
+	dir := filepath.Join(repo, "groups")
+	write := func(path, content string) {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) {
+		if _, err := git(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("sig-foo/groups.yaml", `groups:
+  - email-id: sig-foo@example.com
+    members:
+      - a@example.com
+`)
+	// Files that aren't valid against the current schema are still read.
+	write("sig-bar/groups.yaml", `groups:
+  - email-id: sig-bar@example.com
+    unknown: field
+`)
+	write("README.md", "not a groups file")
+	run("add", "-A")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	write("sig-foo/groups.yaml", `groups:
+  - email-id: sig-foo@example.com
+`)
+
+	groups, err := groupsAtRevision(dir, "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []GoogleGroup{
+		{EmailId: "sig-bar@example.com"},
+		{EmailId: "sig-foo@example.com", Members: []string{"a@example.com"}},
+	}
+	if !reflect.DeepEqual(canonicalGroups(expected, strings.ToLower), canonicalGroups(groups, strings.ToLower)) {
+		t.Errorf("expected groups %v, got %v", expected, groups)
+	}
+
+	if _, err := groupsAtRevision(dir, "does-not-exist"); err == nil {
+		t.Errorf("expected an error for an unknown revision")
+	}
+}
+
+func TestGroupsChangedSinceUnknownRevision(t *testing.T) {
+	defer func(path string) { config.GroupsPath = path }(config.GroupsPath)
+	config.GroupsPath = t.TempDir()
+
+	groups := []GoogleGroup{{EmailId: "sig-foo@example.com"}}
+	if changed, err := groupsChangedSince("HEAD", groups); err == nil {
+		t.Errorf("expected an error when the revision can't be read, got groups %v", changed)
+	}
+}
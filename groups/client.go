@@ -20,10 +20,14 @@ import (
 	"context"
 
 	admin "google.golang.org/api/admin/directory/v1"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
 	groupssettings "google.golang.org/api/groupssettings/v1"
 	"google.golang.org/api/option"
 )
 
+// defaultCustomer is the alias of the customer of the authenticated account.
+const defaultCustomer = "my_customer"
+
 type AdminServiceClient interface {
 	GetGroup(groupKey string) (*admin.Group, error)
 	GetMember(groupKey, memberKey string) (*admin.Member, error)
@@ -35,10 +39,13 @@ type AdminServiceClient interface {
 	UpdateMember(groupKey, memberKey string, member *admin.Member) (*admin.Member, error)
 	DeleteGroup(groupKey string) error
 	DeleteMember(groupKey, memberKey string) error
+	GetUser(userKey string) (*admin.User, error)
+	InsertGroupAlias(groupKey, alias string) (*admin.Alias, error)
+	DeleteGroupAlias(groupKey, alias string) error
 }
 
-func NewAdminServiceClient(ctx context.Context, clientOption option.ClientOption) (AdminServiceClient, error) {
-	adminSvc, err := admin.NewService(ctx, clientOption)
+func NewAdminServiceClient(ctx context.Context, clientOptions ...option.ClientOption) (AdminServiceClient, error) {
+	adminSvc, err := admin.NewService(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +65,44 @@ func (asc *adminServiceClient) GetMember(groupKey, memberKey string) (*admin.Mem
 	return asc.service.Members.Get(groupKey, memberKey).Do()
 }
 
+func (asc *adminServiceClient) GetUser(userKey string) (*admin.User, error) {
+	return asc.service.Users.Get(userKey).Do()
+}
+
+// ListGroups lists all groups of the configured customer, following
+// NextPageToken until every page has been retrieved. The listing is
+// narrowed down by the configured domain and groups-query, if any.
 func (asc *adminServiceClient) ListGroups() (*admin.Groups, error) {
-	return asc.service.Groups.List().Customer("my_customer").OrderBy("email").Do()
+	groups := &admin.Groups{}
+	var resp *admin.Groups
+	var err error
+
+	customer := config.Customer
+	if customer == "" {
+		customer = defaultCustomer
+	}
+	call := asc.service.Groups.List().Customer(customer).OrderBy("email")
+	if config.Domain != "" {
+		call.Domain(config.Domain)
+	}
+	if config.GroupsQuery != "" {
+		call.Query(config.GroupsQuery)
+	}
+	for {
+		resp, err = call.Do()
+		if err != nil {
+			return nil, err
+		}
+
+		groups.Groups = append(groups.Groups, resp.Groups...)
+		if resp.NextPageToken == "" {
+			break
+		}
+
+		call.PageToken(resp.NextPageToken)
+	}
+
+	return groups, nil
 }
 
 func (asc *adminServiceClient) ListMembers(groupKey string) ([]*admin.Member, error) {
@@ -109,6 +152,14 @@ func (asc *adminServiceClient) DeleteMember(groupKey, memberKey string) error {
 	return asc.service.Members.Delete(groupKey, memberKey).Do()
 }
 
+func (asc *adminServiceClient) InsertGroupAlias(groupKey, alias string) (*admin.Alias, error) {
+	return asc.service.Groups.Aliases.Insert(groupKey, &admin.Alias{Alias: alias}).Do()
+}
+
+func (asc *adminServiceClient) DeleteGroupAlias(groupKey, alias string) error {
+	return asc.service.Groups.Aliases.Delete(groupKey, alias).Do()
+}
+
 var _ AdminServiceClient = (*adminServiceClient)(nil)
 
 type GroupServiceClient interface {
@@ -116,8 +167,8 @@ type GroupServiceClient interface {
 	Patch(groupUniqueID string, groups *groupssettings.Groups) (*groupssettings.Groups, error)
 }
 
-func NewGroupServiceClient(ctx context.Context, clientOption option.ClientOption) (GroupServiceClient, error) {
-	groupSvc, err := groupssettings.NewService(ctx, clientOption)
+func NewGroupServiceClient(ctx context.Context, clientOptions ...option.ClientOption) (GroupServiceClient, error) {
+	groupSvc, err := groupssettings.NewService(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -138,3 +189,45 @@ func (gsc *groupServiceClient) Patch(groupUniqueID string, groups *groupssetting
 }
 
 var _ GroupServiceClient = (*groupServiceClient)(nil)
+
+// CloudIdentityClient is the part of the Cloud Identity Groups API used to
+// manage the labels of groups, which the Admin Directory API doesn't expose.
+// Groups are identified by their resource name, of the form groups/{group}.
+type CloudIdentityClient interface {
+	LookupGroupName(email string) (string, error)
+	GetGroup(name string) (*cloudidentity.Group, error)
+	PatchGroupLabels(name string, labels map[string]string) error
+}
+
+func NewCloudIdentityClient(ctx context.Context, clientOptions ...option.ClientOption) (CloudIdentityClient, error) {
+	cloudIdentitySvc, err := cloudidentity.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudIdentityClient{service: cloudIdentitySvc}, nil
+}
+
+type cloudIdentityClient struct {
+	service *cloudidentity.Service
+}
+
+func (cic *cloudIdentityClient) LookupGroupName(email string) (string, error) {
+	resp, err := cic.service.Groups.Lookup().GroupKeyId(email).Do()
+	if err != nil {
+		return "", err
+	}
+	return resp.Name, nil
+}
+
+func (cic *cloudIdentityClient) GetGroup(name string) (*cloudidentity.Group, error) {
+	return cic.service.Groups.Get(name).Do()
+}
+
+// PatchGroupLabels replaces the labels of the group with the given name.
+func (cic *cloudIdentityClient) PatchGroupLabels(name string, labels map[string]string) error {
+	_, err := cic.service.Groups.Patch(name, &cloudidentity.Group{Labels: labels}).UpdateMask("labels").Do()
+	return err
+}
+
+var _ CloudIdentityClient = (*cloudIdentityClient)(nil)
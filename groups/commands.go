@@ -0,0 +1,310 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/net/context"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// options holds the flags shared by the commands.
+type options struct {
+	configFile        string
+	numWorkers        int
+	githubAnnotations bool
+	// changedSince is the git revision since which changed groups are
+	// reconciled, or "" to reconcile all groups.
+	changedSince string
+	// selector selects the groups to reconcile.
+	selector groupSelector
+	// deleteGroups enables deleting the groups that aren't in the config
+	// when reconciling only some groups.
+	deleteGroups bool
+	// purgeGroups are the emails of the groups pending deletion to purge.
+	purgeGroups []string
+	// purgeAll purges all groups pending deletion.
+	purgeAll bool
+	// approveSensitive approves all changes to sensitive groups.
+	approveSensitive bool
+	// approval approves the changes to sensitive groups it lists, if set.
+	approval *sensitiveApproval
+	// graph holds the flags of export-graph and the groups it exports.
+	graph graphOptions
+}
+
+// addFlags adds the shared flags to fs. The flags default to the current
+// values of o, so that flags given before the command are kept. Flags only
+// used when calling the APIs are added if online is true.
+func (o *options) addFlags(fs *flag.FlagSet, online bool) {
+	if o.configFile == "" {
+		o.configFile = defaultConfigFile
+	}
+	if o.numWorkers == 0 {
+		o.numWorkers = defaultNumWorkers
+	}
+	fs.StringVar(&o.configFile, "config", o.configFile, "the config file in yaml format")
+	fs.BoolVar(&o.githubAnnotations, "github-annotations", o.githubAnnotations, "also print errors about groups.yaml files as GitHub Actions annotations")
+	if online {
+		fs.IntVar(&o.numWorkers, "workers", o.numWorkers, "number of concurrent workers to use")
+	}
+	if fs != flag.CommandLine {
+		fs.BoolVar(verbose, "v", *verbose, "log extra information")
+	}
+}
+
+// command is a subcommand of the groups binary.
+type command struct {
+	name string
+	// args describes the arguments of the command in the usage.
+	args string
+	help string
+	run  func(o *options, args []string) error
+}
+
+var commands = []command{
+	{
+		name: "validate",
+		help: "check the config, restrictions and groups.yaml files, without credentials",
+		run:  tenantsCommand("validate", validateMode, false),
+	},
+	{
+		name: "plan",
+		help: "show the changes apply would make, without making them",
+		run:  tenantsCommand("plan", reconcileMode, false),
+	},
+	{
+		name: "apply",
+		help: "reconcile the existing groups with the groups.yaml files",
+		run:  tenantsCommand("apply", reconcileMode, true),
+	},
+	{
+		name: "print",
+		help: "print the existing groups in the groups.yaml format",
+		run:  tenantsCommand("print", printMode, false),
+	},
+	{
+		name: "diff",
+		help: "print how the existing groups differ from the groups.yaml files",
+		run:  tenantsCommand("diff", diffMode, false),
+	},
+	{
+		name: "verify-members",
+		help: "report members that are suspended, don't exist or aren't active",
+		run:  tenantsCommand("verify-members", verifyMembersMode, false),
+	},
+	{
+		name: "whois",
+		args: "<email|github-handle>",
+		help: "list the groups and roles of a person, also through nested groups, without credentials",
+		run:  runWhois,
+	},
+	{
+		name: "schedule",
+		args: "[-all]",
+		help: "list the upcoming scheduled changes of owners, managers and members, without credentials",
+		run:  runSchedule,
+	},
+	{
+		name: "export-graph",
+		args: "[-format <format>]",
+		help: "print the nesting and membership of groups as a Graphviz, Mermaid or JSON graph",
+		run:  runExportGraph,
+	},
+	{
+		name: "offboard",
+		args: "[-plan=false] <email>",
+		help: "remove a person from every groups.yaml file, then print the plan for the edited groups",
+		run:  runOffboard,
+	},
+	{
+		name: "member",
+		args: "add|remove|set-role ...",
+		help: "add or remove a member of a group, or set their role, in the groups.yaml file defining it",
+		run:  runMember,
+	},
+	{
+		name: "purge",
+		args: "[-confirm] -all|<group> ...",
+		help: "save a snapshot of and delete groups pending deletion, without waiting for the grace period",
+		run:  runPurge,
+	},
+	{
+		name: "fmt",
+		args: "[-check] [path ...]",
+		help: "format groups.yaml files, by default those of the config, without credentials",
+		run:  runFmt,
+	},
+	{
+		name: "schema",
+		args: "[groups|restrictions]",
+		help: "print the JSON Schema of groups.yaml or restrictions.yaml files",
+		run: func(o *options, args []string) error {
+			name := "groups"
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return printSchema(name)
+		},
+	},
+}
+
+// findCommand returns the command with the given name, or nil.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// legacyCommand returns the command to run for the flags used before the
+// binary had commands.
+func legacyCommand(confirmChanges, printConfig, verifyMembers bool) (string, error) {
+	switch {
+	case printConfig && verifyMembers:
+		return "", errors.New("-print and -verify-members are mutually exclusive")
+	case printConfig:
+		return "print", nil
+	case verifyMembers:
+		return "verify-members", nil
+	case confirmChanges:
+		return "apply", nil
+	}
+	return "plan", nil
+}
+
+func Usage() {
+	fmt.Fprintf(os.Stderr, `
+Usage: %s [flags] <command> [flags]
+
+Commands:
+`, os.Args[0])
+	for _, c := range commands {
+		name := c.name
+		if c.args != "" {
+			name += " " + c.args
+		}
+		fmt.Fprintf(os.Stderr, "  %-32s %s\n", name, c.help)
+	}
+	fmt.Fprintf(os.Stderr, `
+Run %s <command> -help for the flags of a command.
+Flags given after the command override those given before it.
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+// newFlagSet returns the flag set of the named command, with the shared
+// flags.
+func newFlagSet(name string, o *options, online bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	o.addFlags(fs, online)
+	return fs
+}
+
+// tenantsCommand returns the run function of a command loading the groups
+// of each tenant of the config and running them in mode. Changes are only
+// made if confirmChanges is true.
+func tenantsCommand(name string, mode runMode, confirmChanges bool) func(o *options, args []string) error {
+	return func(o *options, args []string) error {
+		fs := newFlagSet(name, o, mode != validateMode)
+		if mode == reconcileMode {
+			fs.StringVar(&o.changedSince, "changed-since", "", "only reconcile the groups that changed since this git revision, and delete those removed since")
+			fs.Var(&o.selector.groups, "group", "only reconcile the group with this email, can be repeated")
+			fs.Var(&o.selector.paths, "path", "only reconcile the groups of the groups.yaml files matching this glob, relative to the groups-path, can be repeated")
+			fs.Var(&o.selector.excludes, "exclude", "don't reconcile the group with this email or the groups of the groups.yaml files matching this glob, can be repeated")
+			fs.BoolVar(&o.deleteGroups, "delete", false, "delete the groups that aren't in the config even if -group, -path or -exclude is used")
+		}
+		var approvalFile string
+		if mode == reconcileMode && confirmChanges {
+			fs.BoolVar(&o.approveSensitive, "approve-sensitive", false, "also apply the changes to sensitive groups")
+			fs.StringVar(&approvalFile, "approval-file", "", "also apply the changes to sensitive groups listed in this approval file")
+		}
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			return fmt.Errorf("%s takes no arguments, got %q", name, fs.Args())
+		}
+		if approvalFile != "" {
+			approval, err := loadSensitiveApproval(approvalFile)
+			if err != nil {
+				return err
+			}
+			o.approval = approval
+		}
+		return runTenants(o, mode, confirmChanges)
+	}
+}
+
+// runTenants loads the config and runs mode for each of its tenants.
+func runTenants(o *options, mode runMode, confirmChanges bool) error {
+	if (mode == reconcileMode || mode == purgeMode) && !confirmChanges {
+		log.Printf("confirm: %v -- dry-run mode, changes will not be pushed", confirmChanges)
+	}
+	if o.numWorkers < 1 {
+		o.numWorkers = 1
+	}
+	if mode != validateMode {
+		log.Printf("workers: %v", o.numWorkers)
+	}
+
+	err := config.Load(o.configFile, confirmChanges)
+	if err != nil {
+		return err
+	}
+	log.Printf("config: ConfirmChanges:   %v", config.ConfirmChanges)
+
+	ctx := context.Background()
+	var (
+		errs    []error
+		reports []tenantReport
+	)
+	for _, t := range config.Tenants {
+		report := reconcileTenant(ctx, t, o, mode)
+		if report.err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, report.err))
+			if o.githubAnnotations {
+				for _, a := range githubAnnotations(report.err) {
+					fmt.Println(a)
+				}
+			}
+		}
+		reports = append(reports, report)
+	}
+
+	switch mode {
+	case reconcileMode:
+		log.Println(" ======================= Report ========================")
+		for _, r := range reports {
+			log.Println(r)
+		}
+	case validateMode:
+		for _, r := range reports {
+			if r.err == nil {
+				log.Printf("tenant %s: %d groups are valid", r.tenant, r.groups)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
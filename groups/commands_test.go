@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLegacyCommand(t *testing.T) {
+	cases := []struct {
+		confirm, print, verify bool
+		expected               string
+		expectedErr            bool
+	}{
+		{expected: "plan"},
+		{confirm: true, expected: "apply"},
+		{confirm: true, print: true, expected: "print"},
+		{verify: true, expected: "verify-members"},
+		{print: true, verify: true, expectedErr: true},
+	}
+	for _, c := range cases {
+		actual, err := legacyCommand(c.confirm, c.print, c.verify)
+		if c.expectedErr != (err != nil) {
+			t.Errorf("confirm=%v print=%v verify-members=%v: expected error %v, got %v", c.confirm, c.print, c.verify, c.expectedErr, err)
+		}
+		if actual != c.expected {
+			t.Errorf("confirm=%v print=%v verify-members=%v: expected command %q, got %q", c.confirm, c.print, c.verify, c.expected, actual)
+		}
+	}
+}
+
+// TestValidateOffline ensures validate works with a config whose credentials
+// aren't available.
+func TestValidateOffline(t *testing.T) {
+	defer func() {
+		config = Config{}
+	}()
+
+	cases := []struct {
+		desc        string
+		groups      string
+		expectedErr string
+	}{
+		{
+			desc: "valid groups",
+			groups: `groups:
+  - email-id: group1@example.com
+    members:
+      - member@example.com
+`,
+		},
+		{
+			desc: "group of another domain",
+			groups: `groups:
+  - email-id: group1@other.com
+`,
+			expectedErr: "groups.yaml:2:5: group group1@other.com does not belong to domain example.com",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{
+				"config.yaml": `bot-id: bot@example.com
+secret-version: projects/unreachable/secrets/s/versions/latest
+domain: example.com
+`,
+				"restrictions.yaml": `restrictions:
+  - path: "*"
+    allowedGroups:
+      - ".*"
+`,
+				"groups.yaml": c.groups,
+			}
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config = Config{}
+			err := runTenants(&options{configFile: filepath.Join(dir, "config.yaml")}, validateMode, false)
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), c.expectedErr)) {
+				t.Errorf("expected error containing %q, got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
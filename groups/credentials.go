@@ -0,0 +1,191 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"golang.org/x/oauth2/google"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+const (
+	// SecretManagerCredentialSource reads a service account key from
+	// Google Secret Manager. This is the default.
+	SecretManagerCredentialSource = "secret-manager"
+	// KeyFileCredentialSource reads a service account key from a local file.
+	KeyFileCredentialSource = "key-file"
+	// ImpersonationCredentialSource uses Application Default Credentials to
+	// impersonate a service account with domain-wide delegation.
+	ImpersonationCredentialSource = "impersonation"
+)
+
+// credentialScopes are the OAuth scopes requested for every credential source,
+// in addition to the extra scopes of the tenant.
+var credentialScopes = []string{
+	admin.AdminDirectoryUserReadonlyScope,
+	admin.AdminDirectoryGroupScope,
+	admin.AdminDirectoryGroupMemberScope,
+	groupssettings.AppsGroupsSettingsScope,
+}
+
+// CredentialProvider provides the client option used to authenticate calls
+// to the Admin Directory and Groups Settings APIs.
+//
+// Implementations must never include secret material (such as the contents
+// of a service account key) in returned errors or in String.
+type CredentialProvider interface {
+	ClientOption(ctx context.Context) (option.ClientOption, error)
+	// String describes where credentials are obtained from, for logging.
+	String() string
+}
+
+// NewCredentialProvider returns the CredentialProvider selected by the
+// credential-source of the given tenant.
+func NewCredentialProvider(c *Tenant) (CredentialProvider, error) {
+	switch c.CredentialSource {
+	case "", SecretManagerCredentialSource:
+		if c.SecretVersion == "" {
+			return nil, fmt.Errorf("credential-source %q requires secret-version", SecretManagerCredentialSource)
+		}
+		return &secretManagerCredentialProvider{secretVersion: c.SecretVersion, subject: c.BotID, extraScopes: c.extraScopes()}, nil
+	case KeyFileCredentialSource:
+		if c.KeyFile == "" {
+			return nil, fmt.Errorf("credential-source %q requires key-file", KeyFileCredentialSource)
+		}
+		return &keyFileCredentialProvider{path: c.KeyFile, subject: c.BotID, extraScopes: c.extraScopes()}, nil
+	case ImpersonationCredentialSource:
+		if c.ServiceAccount == "" {
+			return nil, fmt.Errorf("credential-source %q requires service-account", ImpersonationCredentialSource)
+		}
+		return &impersonationCredentialProvider{serviceAccount: c.ServiceAccount, subject: c.BotID, extraScopes: c.extraScopes()}, nil
+	default:
+		return nil, fmt.Errorf("unknown credential-source %q", c.CredentialSource)
+	}
+}
+
+// scopes returns credentialScopes followed by extraScopes.
+func scopes(extraScopes []string) []string {
+	return append(append([]string{}, credentialScopes...), extraScopes...)
+}
+
+// jwtClientOption builds a client option from a service account key that
+// acts on behalf of subject through domain-wide delegation.
+func jwtClientOption(ctx context.Context, serviceAccountKey []byte, subject string, extraScopes []string) (option.ClientOption, error) {
+	credential, err := google.JWTConfigFromJSON(serviceAccountKey, scopes(extraScopes)...)
+	if err != nil {
+		// The underlying error is not wrapped since it may quote the key.
+		return nil, errors.New("unable to parse service account key")
+	}
+	credential.Subject = subject
+	return option.WithHTTPClient(credential.Client(ctx)), nil
+}
+
+type secretManagerCredentialProvider struct {
+	secretVersion string
+	subject       string
+	extraScopes   []string
+}
+
+func (p *secretManagerCredentialProvider) ClientOption(ctx context.Context) (option.ClientOption, error) {
+	serviceAccountKey, err := accessSecretVersion(ctx, p.secretVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to access secret-version %s: %w", p.secretVersion, err)
+	}
+	clientOption, err := jwtClientOption(ctx, serviceAccountKey, p.subject, p.extraScopes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to authenticate using key in secret-version %s: %w", p.secretVersion, err)
+	}
+	return clientOption, nil
+}
+
+func (p *secretManagerCredentialProvider) String() string {
+	return fmt.Sprintf("%s (%s)", SecretManagerCredentialSource, p.secretVersion)
+}
+
+type keyFileCredentialProvider struct {
+	path        string
+	subject     string
+	extraScopes []string
+}
+
+func (p *keyFileCredentialProvider) ClientOption(ctx context.Context) (option.ClientOption, error) {
+	serviceAccountKey, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read key-file %s: %w", p.path, err)
+	}
+	clientOption, err := jwtClientOption(ctx, serviceAccountKey, p.subject, p.extraScopes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to authenticate using key-file %s: %w", p.path, err)
+	}
+	return clientOption, nil
+}
+
+func (p *keyFileCredentialProvider) String() string {
+	return fmt.Sprintf("%s (%s)", KeyFileCredentialSource, p.path)
+}
+
+type impersonationCredentialProvider struct {
+	serviceAccount string
+	subject        string
+	extraScopes    []string
+}
+
+func (p *impersonationCredentialProvider) ClientOption(ctx context.Context) (option.ClientOption, error) {
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: p.serviceAccount,
+		Scopes:          scopes(p.extraScopes),
+		Subject:         p.subject,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to impersonate service-account %s: %w", p.serviceAccount, err)
+	}
+	return option.WithTokenSource(ts), nil
+}
+
+func (p *impersonationCredentialProvider) String() string {
+	return fmt.Sprintf("%s (%s)", ImpersonationCredentialSource, p.serviceAccount)
+}
+
+// accessSecretVersion accesses the payload for the given secret version if one exists
+// secretVersion is of the form projects/{project}/secrets/{secret}/versions/{version}
+func accessSecretVersion(ctx context.Context, secretVersion string) ([]byte, error) {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secretmanager client: %w", err)
+	}
+	defer client.Close()
+
+	req := &secretmanagerpb.AccessSecretVersionRequest{
+		Name: secretVersion,
+	}
+
+	result, err := client.AccessSecretVersion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access secret version: %w", err)
+	}
+
+	return result.Payload.Data, nil
+}
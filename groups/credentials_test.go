@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewCredentialProvider(t *testing.T) {
+	testcases := []struct {
+		name        string
+		tenant      Tenant
+		expected    CredentialProvider
+		expectedErr bool
+	}{
+		{
+			name:     "default is secret manager",
+			tenant:   Tenant{BotID: "bot@example.com", SecretVersion: "projects/p/secrets/s/versions/latest"},
+			expected: &secretManagerCredentialProvider{secretVersion: "projects/p/secrets/s/versions/latest", subject: "bot@example.com"},
+		},
+		{
+			name:        "secret manager without secret-version",
+			tenant:      Tenant{CredentialSource: SecretManagerCredentialSource},
+			expectedErr: true,
+		},
+		{
+			name:     "key file",
+			tenant:   Tenant{BotID: "bot@example.com", CredentialSource: KeyFileCredentialSource, KeyFile: "/tmp/key.json"},
+			expected: &keyFileCredentialProvider{path: "/tmp/key.json", subject: "bot@example.com"},
+		},
+		{
+			name:        "key file without key-file",
+			tenant:      Tenant{CredentialSource: KeyFileCredentialSource},
+			expectedErr: true,
+		},
+		{
+			name:     "impersonation",
+			tenant:   Tenant{BotID: "bot@example.com", CredentialSource: ImpersonationCredentialSource, ServiceAccount: "sa@p.iam.gserviceaccount.com"},
+			expected: &impersonationCredentialProvider{serviceAccount: "sa@p.iam.gserviceaccount.com", subject: "bot@example.com"},
+		},
+		{
+			name:     "cloud identity",
+			tenant:   Tenant{BotID: "bot@example.com", CredentialSource: KeyFileCredentialSource, KeyFile: "/tmp/key.json", CloudIdentity: true},
+			expected: &keyFileCredentialProvider{path: "/tmp/key.json", subject: "bot@example.com", extraScopes: []string{"https://www.googleapis.com/auth/cloud-identity.groups"}},
+		},
+		{
+			name:        "impersonation without service-account",
+			tenant:      Tenant{CredentialSource: ImpersonationCredentialSource},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown source",
+			tenant:      Tenant{CredentialSource: "magic"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := NewCredentialProvider(&tc.tenant)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected error, got provider %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestKeyFileCredentialProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	validKey := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(validKey, []byte(`{"type": "service_account", "client_email": "sa@p.iam.gserviceaccount.com", "private_key": "not-a-real-key"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p := &keyFileCredentialProvider{path: validKey, subject: "bot@example.com"}
+	if _, err := p.ClientOption(context.Background()); err != nil {
+		t.Errorf("unexpected error for valid key file: %v", err)
+	}
+
+	// errors must not leak the contents of the key file
+	secret := "super-secret-private-key-material"
+	invalidKey := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidKey, []byte(`{"type": "service_account", "private_key": "`+secret), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p = &keyFileCredentialProvider{path: invalidKey, subject: "bot@example.com"}
+	_, err := p.ClientOption(context.Background())
+	if err == nil {
+		t.Fatalf("expected error for invalid key file")
+	}
+	if strings.Contains(err.Error(), secret) || strings.Contains(p.String(), secret) {
+		t.Errorf("secret material leaked: %v", err)
+	}
+
+	p = &keyFileCredentialProvider{path: filepath.Join(dir, "missing.json")}
+	if _, err := p.ClientOption(context.Background()); err == nil {
+		t.Errorf("expected error for missing key file")
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"gopkg.in/yaml.v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DiffGroups prints to w how each existing group differs from its
+// configuration in groups, as a line diff of both in the groups.yaml format.
+// Only what the configuration specifies is compared: the name, description
+// and aliases if set, the settings, delivery settings and labels listed, and
+// the owners, managers and members. Groups that still have one of their
+// previous email ids are shown with it, and groups as they are reconciled
+// now, see GoogleGroup.Schedule. Existing groups that aren't configured,
+// which apply deletes, are shown as removed.
+func (r *Reconciler) DiffGroups(w io.Writer, groups []GoogleGroup) error {
+	live, err := r.adminService.ListGroups()
+	if err != nil {
+		return fmt.Errorf("unable to list groups: %w", err)
+	}
+	existing := map[string]*admin.Group{}
+	for _, g := range live.Groups {
+		existing[strings.ToLower(g.Email)] = g
+	}
+
+	var (
+		errs    []error
+		differs int
+	)
+	for _, want := range groups {
+		want = want.asOf(now())
+		var have *GoogleGroup
+		if g := takeGroup(existing, want); g != nil {
+			h, err := r.existingGroup(g, want)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			have = &h
+		}
+		want := comparableGroup(want)
+		d, err := printGroupDiff(w, want.EmailId, have, &want)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if d {
+			differs++
+		}
+	}
+
+	var removed []string
+	for email := range existing {
+		removed = append(removed, email)
+	}
+	sort.Strings(removed)
+	for _, email := range removed {
+		g := existing[email]
+		have := GoogleGroup{EmailId: g.Email}
+		if _, err := printGroupDiff(w, g.Email, &have, nil); err != nil {
+			errs = append(errs, err)
+		}
+		differs++
+	}
+
+	log.Printf("%d groups differ from their configuration", differs)
+	return utilerrors.NewAggregate(errs)
+}
+
+// takeGroup removes the existing group with the email id of want, or else
+// with one of its previous email ids, from existing, keyed by lowercased
+// email, and returns it. It returns nil if there is none.
+func takeGroup(existing map[string]*admin.Group, want GoogleGroup) *admin.Group {
+	for _, email := range append([]string{want.EmailId}, want.PreviousEmailIds...) {
+		if g, ok := existing[strings.ToLower(email)]; ok {
+			delete(existing, strings.ToLower(email))
+			return g
+		}
+	}
+	return nil
+}
+
+// existingGroup returns the state of the existing group g, restricted to
+// what want specifies. The email id of g is kept if want renames it.
+func (r *Reconciler) existingGroup(g *admin.Group, want GoogleGroup) (GoogleGroup, error) {
+	have := GoogleGroup{EmailId: want.EmailId}
+	if !strings.EqualFold(g.Email, want.EmailId) {
+		have.EmailId = g.Email
+	}
+	if want.specifies("name") {
+		have.Name = g.Name
+	}
+	if want.specifies("description") {
+		have.Description = g.Description
+	}
+	if want.specifies("aliases") {
+		have.Aliases = g.Aliases
+	}
+
+	if len(want.Settings) > 0 {
+		settings, err := r.groupService.Get(g.Email)
+		if err != nil {
+			return have, fmt.Errorf("unable to retrieve group info for group %s: %w", g.Email, err)
+		}
+		have.Settings = map[string]string{}
+		v := reflect.ValueOf(settings).Elem()
+		for key, value := range want.Settings {
+			if f := v.FieldByName(key); f.IsValid() && f.Kind() == reflect.String {
+				have.Settings[key] = f.String()
+			} else {
+				// Settings that aren't settings of Google Groups, such as
+				// ReconcileMembers, configure the reconciler rather than
+				// the group, so they never differ.
+				have.Settings[key] = value
+			}
+		}
+	}
+
+	if len(want.Labels) > 0 && r.labelService != nil {
+		labels, err := r.labelService.GetLabels(g.Email)
+		if err != nil {
+			return have, fmt.Errorf("unable to retrieve labels of group %s: %w", g.Email, err)
+		}
+		have.Labels = map[string]string{}
+		for key := range want.Labels {
+			if value, ok := labels[key]; ok {
+				have.Labels[key] = value
+			}
+		}
+	}
+
+	members, err := r.adminService.ListMembers(g.Email)
+	if err != nil {
+		return have, fmt.Errorf("unable to retrieve members in group %s: %w", g.Email, err)
+	}
+	for _, m := range members {
+		switch m.Role {
+		case OwnerRole:
+			have.Owners = append(have.Owners, m.Email)
+		case ManagerRole:
+			have.Managers = append(have.Managers, m.Email)
+		case MemberRole:
+			have.Members = append(have.Members, m.Email)
+		}
+		if delivery := want.DeliveryFor(m.Email); delivery != "" {
+			if have.Delivery == nil {
+				have.Delivery = map[string]string{}
+			}
+			have.Delivery[strings.ToLower(m.Email)] = m.DeliverySettings
+		}
+	}
+	return comparableGroup(have), nil
+}
+
+// comparableGroup returns g with its addresses in canonical form, per
+// CanonicalEmail, and sorted, so that groups only differing in the order of
+// addresses or in how they are written compare equal. The fields g sets are
+// kept, so that clearing a field is a change, but not its previous email ids
+// and schedule, which aren't part of its state.
+func comparableGroup(g GoogleGroup) GoogleGroup {
+	fields := g.source.fields
+	g = canonicalGroups([]GoogleGroup{g}, CanonicalEmail)[0]
+	g.source.fields = fields
+	g.PreviousEmailIds = nil
+	g.Schedule = nil
+	if g.Aliases != nil {
+		aliases := make([]string, 0, len(g.Aliases))
+		for _, a := range g.Aliases {
+			aliases = append(aliases, CanonicalEmail(a))
+		}
+		sort.Strings(aliases)
+		g.Aliases = aliases
+	}
+	if g.Delivery != nil {
+		delivery := map[string]string{}
+		for email, d := range g.Delivery {
+			delivery[CanonicalEmail(email)] = d
+		}
+		g.Delivery = delivery
+	}
+	return g
+}
+
+// printGroupDiff prints the diff between the group have and the group want,
+// where nil stands for a group that doesn't exist or isn't configured, to w
+// if they differ. It returns whether they differ.
+func printGroupDiff(w io.Writer, email string, have, want *GoogleGroup) (bool, error) {
+	a, err := groupLines(have)
+	if err != nil {
+		return false, err
+	}
+	b, err := groupLines(want)
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(a, b) {
+		return false, nil
+	}
+
+	from, to := "/dev/null", "configured "+email
+	if have != nil {
+		// The existing group has a previous email id if it's renamed.
+		from = "existing " + have.EmailId
+	}
+	if want == nil {
+		to = "/dev/null"
+	}
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", from, to)
+	for _, line := range diffLines(a, b) {
+		fmt.Fprintln(w, line)
+	}
+	return true, nil
+}
+
+// groupLines returns the lines of g in the groups.yaml format, or none if g
+// is nil.
+func groupLines(g *GoogleGroup) ([]string, error) {
+	if g == nil {
+		return nil, nil
+	}
+	out, err := yaml.Marshal(g)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate yaml for group %s: %w", g.EmailId, err)
+	}
+	return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"), nil
+}
+
+// diffLines returns the lines of a and b, prefixed with "-" if they are only
+// in a, "+" if they are only in b or " " if they are in both, following a
+// longest common subsequence of a and b.
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var res []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			res = append(res, " "+a[i])
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			res = append(res, "-"+a[i])
+			i++
+		default:
+			res = append(res, "+"+b[j])
+			j++
+		}
+	}
+	return res
+}
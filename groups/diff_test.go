@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/k8s.io/groups/fake"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"email-id: a@example.com", "members:", "  - b@example.com", "  - c@example.com"}
+	b := []string{"email-id: a@example.com", "members:", "  - c@example.com", "  - d@example.com"}
+	expected := []string{" email-id: a@example.com", " members:", "-  - b@example.com", "   - c@example.com", "+  - d@example.com"}
+	if actual := diffLines(a, b); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestComparableGroup(t *testing.T) {
+	configured := GoogleGroup{
+		EmailId:  "group1@email.com",
+		Owners:   []string{"F.o.o+k8s@gmail.com"},
+		Members:  []string{"Bar@example.com", "bar@googlemail.com"},
+		Delivery: map[string]string{"f.o.o@gmail.com": "DIGEST"},
+	}
+	existing := GoogleGroup{
+		EmailId:  "group1@email.com",
+		Owners:   []string{"foo@gmail.com"},
+		Members:  []string{"bar@gmail.com", "bar@example.com"},
+		Delivery: map[string]string{"foo@gmail.com": "DIGEST"},
+	}
+	if a, b := comparableGroup(configured), comparableGroup(existing); !reflect.DeepEqual(a, b) {
+		t.Errorf("expected groups only differing in how addresses are written to compare equal, got %+v and %+v", a, b)
+	}
+}
+
+func TestDiffGroupsEndToEnd(t *testing.T) {
+	server := fake.NewAugmentedFakeServer()
+	defer server.Close()
+	server.GsGroups["group1@email.com"].WhoCanJoin = "CAN_REQUEST_TO_JOIN"
+
+	reconciler, err := NewReconciler(context.Background(), 1, server.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("error creating reconciler: %v", err)
+	}
+
+	groups := []GoogleGroup{
+		{
+			EmailId:  "group1@email.com",
+			Settings: map[string]string{"WhoCanJoin": "INVITED_CAN_JOIN"},
+			Members:  []string{"M1-group1@email.com", "m3-group1@email.com"},
+			Managers: []string{"m2-group1@email.com"},
+		},
+		{
+			EmailId: "group3@email.com",
+			Members: []string{"m1-group3@email.com"},
+		},
+	}
+	expected := `--- existing group1@email.com
++++ configured group1@email.com
+ email-id: group1@email.com
+ name: ""
+ description: ""
+ settings:
+-    WhoCanJoin: CAN_REQUEST_TO_JOIN
++    WhoCanJoin: INVITED_CAN_JOIN
+ managers:
+     - m2-group1@email.com
+ members:
+     - m1-group1@email.com
++    - m3-group1@email.com
+--- /dev/null
++++ configured group3@email.com
++email-id: group3@email.com
++name: ""
++description: ""
++members:
++    - m1-group3@email.com
+--- existing group2@email.com
++++ /dev/null
+-email-id: group2@email.com
+-name: ""
+-description: ""
+`
+	var out bytes.Buffer
+	if err := reconciler.DiffGroups(&out, groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestDiffGroupsRenamed(t *testing.T) {
+	server := fake.NewAugmentedFakeServer()
+	defer server.Close()
+
+	reconciler, err := NewReconciler(context.Background(), 1, server.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("error creating reconciler: %v", err)
+	}
+
+	groups := []GoogleGroup{
+		{
+			EmailId:  "group1@email.com",
+			Members:  []string{"m1-group1@email.com"},
+			Managers: []string{"m2-group1@email.com"},
+		},
+		{
+			EmailId:          "group3@email.com",
+			PreviousEmailIds: []string{"Group2@email.com"},
+			Owners:           []string{"m2-group2@email.com"},
+			Members:          []string{"m1-group2@email.com"},
+		},
+	}
+	expected := `--- existing group2@email.com
++++ configured group3@email.com
+-email-id: group2@email.com
++email-id: group3@email.com
+ name: ""
+ description: ""
+ owners:
+     - m2-group2@email.com
+ members:
+     - m1-group2@email.com
+`
+	var out bytes.Buffer
+	if err := reconciler.DiffGroups(&out, groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"k8s.io/k8s.io/groups/fake"
+)
+
+// serverState returns a state backed by the data held by the fake server, so
+// that the result of a reconcile can be checked without going through the
+// API clients under test.
+func serverState(server *fake.FakeServer) state {
+	return state{
+		adminClient: &fake.FakeAdminServiceClient{Groups: server.Groups, Members: server.Members},
+		groupClient: &fake.FakeGroupServiceClient{GsGroups: server.GsGroups},
+	}
+}
+
+// TestReconcileGroupsEndToEnd runs the real Reconciler, including the
+// Directory and Groups Settings API clients, against fake.FakeServer.
+func TestReconcileGroupsEndToEnd(t *testing.T) {
+	deleteRightAway(t)
+	group1Settings := map[string]string{
+		"AllowExternalMembers":     "true",
+		"WhoCanJoin":               "CAN_REQUEST_TO_JOIN",
+		"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+		"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+		"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+		"WhoCanModerateMembers":    "OWNERS_AND_MANAGERS",
+		"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+		"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+		"MessageModerationLevel":   "MODERATE_NONE",
+		"MembersCanPostAsTheGroup": "true",
+	}
+	group2Settings := map[string]string{
+		"AllowExternalMembers":     "true",
+		"WhoCanJoin":               "INVITED_CAN_JOIN",
+		"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+		"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+		"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+		"WhoCanModerateMembers":    "OWNERS_ONLY",
+		"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+		"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+		"MessageModerationLevel":   "MODERATE_NONE",
+		"MembersCanPostAsTheGroup": "false",
+	}
+	copySettings := func(s map[string]string) map[string]string {
+		res := make(map[string]string, len(s))
+		for k, v := range s {
+			res[k] = v
+		}
+		return res
+	}
+
+	cases := []struct {
+		desc     string
+		pageSize int
+		// dryRun disables ConfirmChanges, in which case the server state is
+		// expected to be left untouched.
+		dryRun       bool
+		desiredState []GoogleGroup
+	}{
+		{
+			desc: "state matches, nothing to reconcile",
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group2@email.com"},
+					Owners:   []string{"m2-group2@email.com"},
+				},
+			},
+		},
+		{
+			desc:     "members added and removed across multiple pages",
+			pageSize: 1,
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com", "m3-group1@email.com", "m4-group1@email.com"},
+					Owners:   []string{"m5-group1@email.com"},
+				},
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group2@email.com", "m2-group2@email.com"},
+					Managers: []string{"m3-group2@email.com"},
+				},
+			},
+		},
+		{
+			desc: "group3 added with members and settings",
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group2@email.com"},
+					Owners:   []string{"m2-group2@email.com"},
+				},
+				{
+					EmailId: "group3@email.com", Name: "group3", Description: "group3",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group3@email.com"},
+					Owners:   []string{"m2-group3@email.com"},
+				},
+			},
+		},
+		{
+			desc: "member delivery changed and nested group added",
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com", "group2@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+					Delivery: map[string]string{
+						"m1-group1@email.com": DigestDelivery,
+						"group2@email.com":    NoneDelivery,
+					},
+				},
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group2@email.com"},
+					Owners:   []string{"m2-group2@email.com"},
+				},
+			},
+		},
+		{
+			desc: "group2 deleted",
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1 renamed",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+			},
+		},
+		{
+			desc: "group2 renamed to group3, keeping its members and settings",
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+				{
+					EmailId: "group3@email.com", PreviousEmailIds: []string{"group2@email.com"},
+					Name: "group3", Description: "group2",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group2@email.com"},
+					Owners:   []string{"m2-group2@email.com"},
+				},
+			},
+		},
+		{
+			desc:     "group2 deleted with a paginated group listing",
+			pageSize: 1,
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+			},
+		},
+		{
+			desc:   "dry-run of a new group skips missing group (404) errors",
+			dryRun: true,
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: copySettings(group1Settings),
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: copySettings(group2Settings),
+					Members:  []string{"m1-group2@email.com"},
+					Owners:   []string{"m2-group2@email.com"},
+				},
+				{
+					EmailId: "group3@email.com", Name: "group3", Description: "group3",
+					Members: []string{"m1-group3@email.com"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			config.ConfirmChanges = !c.dryRun
+			defer func() { config.ConfirmChanges = true }()
+
+			server := fake.NewAugmentedFakeServer()
+			defer server.Close()
+			server.PageSize = c.pageSize
+			groupsConfig.Groups = c.desiredState
+
+			reconciler, err := NewReconciler(context.Background(), 5, server.ClientOptions()...)
+			if err != nil {
+				t.Fatalf("error creating reconciler: %v", err)
+			}
+			reconciler.snapshots = memorySnapshotStore{}
+			if err := reconciler.ReconcileGroups(c.desiredState); err != nil {
+				t.Errorf("error reconciling groups: %v", err)
+			}
+
+			expectedState := c.desiredState
+			if c.dryRun {
+				expectedState = expectedState[:2]
+			}
+			if err := serverState(server).isReconciled(expectedState); err != nil {
+				t.Errorf("reconciliation unsuccessful: %v", err)
+			}
+			for _, g := range expectedState {
+				for _, m := range server.Members[g.EmailId] {
+					_, isGroup := server.Groups[m.Email]
+					if isGroup != (m.Type == "GROUP") {
+						t.Errorf("unexpected type %q for member %s of %s", m.Type, m.Email, g.EmailId)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestListGroupsEndToEnd(t *testing.T) {
+	server := fake.NewAugmentedFakeServer()
+	defer server.Close()
+	server.Groups["group3@other.com"] = &admin.Group{Email: "group3@other.com", Name: "group3"}
+	server.Groups["k8s-infra-foo@email.com"] = &admin.Group{Email: "k8s-infra-foo@email.com", Name: "k8s-infra-foo"}
+
+	client, err := NewAdminServiceClient(context.Background(), server.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	cases := []struct {
+		desc           string
+		pageSize       int
+		domain         string
+		query          string
+		expectedGroups []string
+	}{
+		{
+			desc:           "all groups of the customer",
+			expectedGroups: []string{"group1@email.com", "group2@email.com", "group3@other.com", "k8s-infra-foo@email.com"},
+		},
+		{
+			desc:           "all groups of the customer, one group per page",
+			pageSize:       1,
+			expectedGroups: []string{"group1@email.com", "group2@email.com", "group3@other.com", "k8s-infra-foo@email.com"},
+		},
+		{
+			desc:           "groups of a domain",
+			pageSize:       2,
+			domain:         "email.com",
+			expectedGroups: []string{"group1@email.com", "group2@email.com", "k8s-infra-foo@email.com"},
+		},
+		{
+			desc:           "groups matching a query",
+			pageSize:       1,
+			query:          "email:k8s-infra*",
+			expectedGroups: []string{"k8s-infra-foo@email.com"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			server.PageSize = c.pageSize
+			config.Domain = c.domain
+			config.GroupsQuery = c.query
+			defer func() {
+				config.Domain = ""
+				config.GroupsQuery = ""
+			}()
+
+			groups, err := client.ListGroups()
+			if err != nil {
+				t.Fatalf("error listing groups: %v", err)
+			}
+			var actual []string
+			for _, g := range groups.Groups {
+				actual = append(actual, g.Email)
+			}
+			if !reflect.DeepEqual(c.expectedGroups, actual) {
+				t.Errorf("expected groups %v, got %v", c.expectedGroups, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+)
+
+// emailDomainRule describes how the local part of addresses in a domain is
+// interpreted by the mail provider of that domain.
+type emailDomainRule struct {
+	// canonicalDomain replaces the domain if set, for domains that are
+	// aliases of another domain.
+	canonicalDomain string
+	// ignoreDots is set if dots in the local part are not significant.
+	ignoreDots bool
+	// ignorePlusSuffix is set if everything from the first "+" in the local
+	// part is a sub-address of the same mailbox.
+	ignorePlusSuffix bool
+}
+
+// emailDomainRules are the domain specific canonicalization rules. Addresses
+// of all other domains are only case folded, since their provider may treat
+// "f.oo@" and "foo@" as different mailboxes.
+var emailDomainRules = map[string]emailDomainRule{
+	"gmail.com":      {ignoreDots: true, ignorePlusSuffix: true},
+	"googlemail.com": {canonicalDomain: "gmail.com", ignoreDots: true, ignorePlusSuffix: true},
+}
+
+// CanonicalEmail returns the canonical form of an e-mail address, such that
+// two addresses delivering to the same mailbox have the same canonical form:
+// - email addresses are case-insensitive (e.g. FOO@bar.com == foo@bar.com)
+// - for gmail.com, local parts are dot-insensitive (e.g. foo@gmail.com == f.o.o@gmail.com)
+// - for gmail.com, "+" sub-addresses are ignored (e.g. foo@gmail.com == foo+k8s@gmail.com)
+// - googlemail.com is an alias of gmail.com
+//
+// Strings that aren't e-mail addresses with exactly one @ are only case folded.
+func CanonicalEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || strings.Contains(domain, "@") {
+		return email
+	}
+
+	rule, ok := emailDomainRules[domain]
+	if !ok {
+		return email
+	}
+	if rule.canonicalDomain != "" {
+		domain = rule.canonicalDomain
+	}
+	if rule.ignorePlusSuffix {
+		local, _, _ = strings.Cut(local, "+")
+	}
+	if rule.ignoreDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// checkDuplicateMembers returns an error for each address of the group that
+// is the same as another address of the group according to CanonicalEmail,
+// regardless of the roles they are listed under, unless the address changes
+// role on a date: it is scheduled in both roles at times that don't overlap.
+func checkDuplicateMembers(g GoogleGroup) []error {
+	var errs []error
+	type entry struct{ email, role string }
+	seen := map[string][]entry{}
+	for _, list := range []struct {
+		role    string
+		members []string
+	}{
+		{OwnerRole, g.Owners},
+		{ManagerRole, g.Managers},
+		{MemberRole, g.Members},
+	} {
+		for i, m := range list.members {
+			canonical := CanonicalEmail(m)
+			duplicate := false
+			for _, prev := range seen[canonical] {
+				if g.scheduledApart(m, prev.role, list.role) {
+					// The address changes role on a date.
+					continue
+				}
+				pos := g.MemberPosition(list.role, i)
+				if prev.email == m {
+					errs = append(errs, errorAt(pos, "group %q lists %q more than once (as %s and %s)", g.EmailId, m, prev.role, list.role))
+				} else {
+					errs = append(errs, errorAt(pos, "group %q lists the same person as %q (%s) and %q (%s)", g.EmailId, prev.email, prev.role, m, list.role))
+				}
+				duplicate = true
+				break
+			}
+			if !duplicate {
+				seen[canonical] = append(seen[canonical], entry{email: m, role: list.role})
+			}
+		}
+	}
+	return errs
+}
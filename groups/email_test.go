@@ -0,0 +1,133 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalEmail(t *testing.T) {
+	testcases := []struct {
+		email    string
+		expected string
+	}{
+		{email: "", expected: ""},
+		{email: "Foo@Bar.com", expected: "foo@bar.com"},
+		{email: " foo@bar.com ", expected: "foo@bar.com"},
+		{email: "f.o.o@bar.com", expected: "f.o.o@bar.com"},
+		{email: "foo+k8s@bar.com", expected: "foo+k8s@bar.com"},
+		{email: "F.o.o@gmail.com", expected: "foo@gmail.com"},
+		{email: "f.oo+k8s.io@gmail.com", expected: "foo@gmail.com"},
+		{email: "F.oo@GoogleMail.com", expected: "foo@gmail.com"},
+		{email: "not-an-email", expected: "not-an-email"},
+		{email: "a@b@gmail.com", expected: "a@b@gmail.com"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.email, func(t *testing.T) {
+			if actual := CanonicalEmail(tc.email); actual != tc.expected {
+				t.Errorf("expected CanonicalEmail(%q) to be %q, got %q", tc.email, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateMembers(t *testing.T) {
+	changed := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := changed.Add(-time.Hour)
+	testcases := []struct {
+		name     string
+		group    GoogleGroup
+		expected []string
+	}{
+		{
+			name: "no duplicates",
+			group: GoogleGroup{
+				EmailId: "group@kubernetes.io",
+				Owners:  []string{"owner@gmail.com"},
+				Members: []string{"f.oo@example.com", "foo@example.com"},
+			},
+		},
+		{
+			name: "exact duplicate across roles",
+			group: GoogleGroup{
+				EmailId:  "group@kubernetes.io",
+				Managers: []string{"foo@example.com"},
+				Members:  []string{"foo@example.com"},
+			},
+			expected: []string{`"foo@example.com" more than once (as MANAGER and MEMBER)`},
+		},
+		{
+			name: "role change on a date",
+			group: GoogleGroup{
+				EmailId:  "group@kubernetes.io",
+				Managers: []string{"foo@example.com"},
+				Members:  []string{"foo@example.com"},
+				Schedule: map[string]MemberSchedule{"foo@example.com": {Roles: map[string]RoleSchedule{
+					MemberRole:  {Until: &changed},
+					ManagerRole: {From: &changed},
+				}}},
+			},
+		},
+		{
+			name: "overlapping roles",
+			group: GoogleGroup{
+				EmailId:  "group@kubernetes.io",
+				Managers: []string{"foo@example.com"},
+				Members:  []string{"foo@example.com"},
+				Schedule: map[string]MemberSchedule{"foo@example.com": {Roles: map[string]RoleSchedule{
+					MemberRole:  {Until: &changed},
+					ManagerRole: {From: &before},
+				}}},
+			},
+			expected: []string{`"foo@example.com" more than once (as MANAGER and MEMBER)`},
+		},
+		{
+			name: "one schedule for two roles",
+			group: GoogleGroup{
+				EmailId:  "group@kubernetes.io",
+				Managers: []string{"foo@example.com"},
+				Members:  []string{"foo@example.com"},
+				Schedule: map[string]MemberSchedule{"foo@example.com": {Until: &changed}},
+			},
+			expected: []string{`"foo@example.com" more than once (as MANAGER and MEMBER)`},
+		},
+		{
+			name: "same person under two spellings",
+			group: GoogleGroup{
+				EmailId: "group@kubernetes.io",
+				Owners:  []string{"Foo.Bar@gmail.com"},
+				Members: []string{"foobar+k8s@googlemail.com"},
+			},
+			expected: []string{`"Foo.Bar@gmail.com" (OWNER) and "foobar+k8s@googlemail.com" (MEMBER)`},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := checkDuplicateMembers(tc.group)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got: %v", len(tc.expected), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.expected[i]) {
+					t.Errorf("expected error to contain %s, got: %v", tc.expected[i], err)
+				}
+			}
+		})
+	}
+}
@@ -17,10 +17,11 @@ limitations under the License.
 package fake
 
 import (
-	"fmt"
+	"strings"
 	"sync"
 
 	admin "google.golang.org/api/admin/directory/v1"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
 	groupssettings "google.golang.org/api/groupssettings/v1"
 )
 
@@ -31,12 +32,20 @@ type FakeAdminServiceClient struct {
 	// Members is a mapping from groupKey -> members of that group
 	// Members of a group are a mapping from memberKey -> *admin.Member
 	Members map[string]map[string]*admin.Member
+	// Users is a mapping from user email to *admin.User
+	Users map[string]*admin.User
 	// OnGroupInsert is a callback function called whenever an
 	// Insert operation is done for a group. This is nescessary
 	// because creating a group also involves reflecting that
 	// creation in the group service fake client, where the GsGroups
 	// map takes the group email as the key.
 	onGroupInsert func(string)
+	// onGroupRename is a callback function called whenever an Update
+	// operation changes the email of a group, with the previous and the
+	// new email, for the same reason.
+	onGroupRename func(string, string)
+
+	FaultInjector
 
 	mutex sync.RWMutex
 }
@@ -45,6 +54,7 @@ func NewFakeAdminServiceClient() *FakeAdminServiceClient {
 	return &FakeAdminServiceClient{
 		Groups:  make(map[string]*admin.Group),
 		Members: make(map[string]map[string]*admin.Member),
+		Users:   make(map[string]*admin.User),
 	}
 }
 
@@ -90,6 +100,13 @@ func NewAugmentedFakeAdminServiceClient() *FakeAdminServiceClient {
 		},
 	}
 
+	fakeClient.Users = map[string]*admin.User{}
+	for _, members := range fakeClient.Members {
+		for email := range members {
+			fakeClient.Users[email] = &admin.User{PrimaryEmail: email, Id: email}
+		}
+	}
+
 	return fakeClient
 }
 
@@ -97,34 +114,55 @@ func (fasc *FakeAdminServiceClient) RegisterCallback(onGroupInsert func(string))
 	fasc.onGroupInsert = onGroupInsert
 }
 
+func (fasc *FakeAdminServiceClient) RegisterRenameCallback(onGroupRename func(string, string)) {
+	fasc.onGroupRename = onGroupRename
+}
+
 func (fasc *FakeAdminServiceClient) GetGroup(groupKey string) (*admin.Group, error) {
+	if err := fasc.injectFault("GetGroup", groupKey); err != nil {
+		return nil, err
+	}
 	fasc.mutex.RLock()
 	defer fasc.mutex.RUnlock()
 	group, ok := fasc.Groups[groupKey]
 	if !ok {
-		return nil, fmt.Errorf("group key %s not found", groupKey)
+		// Like the Directory API, groups can be fetched by their aliases.
+		for _, g := range fasc.Groups {
+			for _, alias := range g.Aliases {
+				if alias == groupKey {
+					return g, nil
+				}
+			}
+		}
+		return nil, notFound("group key %s not found", groupKey)
 	}
 
 	return group, nil
 }
 
 func (fasc *FakeAdminServiceClient) GetMember(groupKey, memberKey string) (*admin.Member, error) {
+	if err := fasc.injectFault("GetMember", groupKey); err != nil {
+		return nil, err
+	}
 	fasc.mutex.RLock()
 	defer fasc.mutex.RUnlock()
 	members, ok := fasc.Members[groupKey]
 	if !ok {
-		return nil, fmt.Errorf("group with group key %s not found", groupKey)
+		return nil, notFound("group with group key %s not found", groupKey)
 	}
 
 	member, ok := members[memberKey]
 	if !ok {
-		return nil, fmt.Errorf("member with groupKey %s and memberKey %s not found", groupKey, memberKey)
+		return nil, notFound("member with groupKey %s and memberKey %s not found", groupKey, memberKey)
 	}
 
 	return member, nil
 }
 
 func (fasc *FakeAdminServiceClient) ListGroups() (*admin.Groups, error) {
+	if err := fasc.injectFault("ListGroups", ""); err != nil {
+		return nil, err
+	}
 	fasc.mutex.RLock()
 	defer fasc.mutex.RUnlock()
 	groups := &admin.Groups{}
@@ -135,22 +173,31 @@ func (fasc *FakeAdminServiceClient) ListGroups() (*admin.Groups, error) {
 }
 
 func (fasc *FakeAdminServiceClient) ListMembers(groupKey string) ([]*admin.Member, error) {
+	if err := fasc.injectFault("ListMembers", groupKey); err != nil {
+		return nil, err
+	}
 	fasc.mutex.RLock()
 	defer fasc.mutex.RUnlock()
 	_, ok := fasc.Members[groupKey]
 	if !ok {
-		return nil, fmt.Errorf("groupKey %s not found", groupKey)
+		return nil, notFound("groupKey %s not found", groupKey)
 	}
 
+	// Return copies so that callers modifying a member without a successful
+	// update, e.g. because of an injected fault, don't change the fake state.
 	members := &admin.Members{}
 	for _, member := range fasc.Members[groupKey] {
-		members.Members = append(members.Members, member)
+		m := *member
+		members.Members = append(members.Members, &m)
 	}
 
 	return members.Members, nil
 }
 
 func (fasc *FakeAdminServiceClient) InsertGroup(group *admin.Group) (*admin.Group, error) {
+	if err := fasc.injectFault("InsertGroup", group.Email); err != nil {
+		return nil, err
+	}
 	fasc.mutex.Lock()
 	defer fasc.mutex.Unlock()
 	fasc.Groups[group.Email] = group
@@ -163,11 +210,14 @@ func (fasc *FakeAdminServiceClient) InsertGroup(group *admin.Group) (*admin.Grou
 }
 
 func (fasc *FakeAdminServiceClient) InsertMember(groupKey string, member *admin.Member) (*admin.Member, error) {
+	if err := fasc.injectFault("InsertMember", groupKey); err != nil {
+		return nil, err
+	}
 	fasc.mutex.Lock()
 	defer fasc.mutex.Unlock()
 	_, ok := fasc.Members[groupKey]
 	if !ok {
-		return nil, fmt.Errorf("groupKey %s not found", groupKey)
+		return nil, notFound("groupKey %s not found", groupKey)
 	}
 
 	fasc.Members[groupKey][member.Email] = member
@@ -175,28 +225,49 @@ func (fasc *FakeAdminServiceClient) InsertMember(groupKey string, member *admin.
 }
 
 func (fasc *FakeAdminServiceClient) UpdateGroup(groupKey string, group *admin.Group) (*admin.Group, error) {
+	if err := fasc.injectFault("UpdateGroup", groupKey); err != nil {
+		return nil, err
+	}
 	fasc.mutex.Lock()
 	defer fasc.mutex.Unlock()
-	_, ok := fasc.Groups[groupKey]
+	existing, ok := fasc.Groups[groupKey]
 	if !ok {
-		return nil, fmt.Errorf("group key %s not found", groupKey)
+		return nil, notFound("group key %s not found", groupKey)
+	}
+
+	// Aliases are read-only, see InsertGroupAlias and DeleteGroupAlias.
+	group.Aliases = existing.Aliases
+	if group.Email == "" || group.Email == groupKey {
+		fasc.Groups[groupKey] = group
+		return group, nil
 	}
 
-	fasc.Groups[groupKey] = group
+	// Changing the email of a group keeps the previous one as an alias.
+	group.Aliases = append(append([]string{}, group.Aliases...), groupKey)
+	delete(fasc.Groups, groupKey)
+	fasc.Groups[group.Email] = group
+	fasc.Members[group.Email] = fasc.Members[groupKey]
+	delete(fasc.Members, groupKey)
+	if fasc.onGroupRename != nil {
+		fasc.onGroupRename(groupKey, group.Email)
+	}
 	return group, nil
 }
 
 func (fasc *FakeAdminServiceClient) UpdateMember(groupKey, memberKey string, member *admin.Member) (*admin.Member, error) {
+	if err := fasc.injectFault("UpdateMember", groupKey); err != nil {
+		return nil, err
+	}
 	fasc.mutex.Lock()
 	defer fasc.mutex.Unlock()
 	_, ok := fasc.Members[groupKey]
 	if !ok {
-		return nil, fmt.Errorf("group with group key %s not found", groupKey)
+		return nil, notFound("group with group key %s not found", groupKey)
 	}
 
 	_, ok = fasc.Members[groupKey][memberKey]
 	if !ok {
-		return nil, fmt.Errorf("member with groupKey %s and memberKey %s not found", groupKey, memberKey)
+		return nil, notFound("member with groupKey %s and memberKey %s not found", groupKey, memberKey)
 	}
 
 	fasc.Members[groupKey][memberKey] = member
@@ -204,11 +275,14 @@ func (fasc *FakeAdminServiceClient) UpdateMember(groupKey, memberKey string, mem
 }
 
 func (fasc *FakeAdminServiceClient) DeleteGroup(groupKey string) error {
+	if err := fasc.injectFault("DeleteGroup", groupKey); err != nil {
+		return err
+	}
 	fasc.mutex.Lock()
 	defer fasc.mutex.Unlock()
 	_, ok := fasc.Groups[groupKey]
 	if !ok {
-		return fmt.Errorf("group key %s not found", groupKey)
+		return notFound("group key %s not found", groupKey)
 	}
 
 	delete(fasc.Groups, groupKey)
@@ -216,26 +290,81 @@ func (fasc *FakeAdminServiceClient) DeleteGroup(groupKey string) error {
 }
 
 func (fasc *FakeAdminServiceClient) DeleteMember(groupKey, memberKey string) error {
+	if err := fasc.injectFault("DeleteMember", groupKey); err != nil {
+		return err
+	}
 	fasc.mutex.Lock()
 	defer fasc.mutex.Unlock()
 	_, ok := fasc.Members[groupKey]
 	if !ok {
-		return fmt.Errorf("group with group key %s not found", groupKey)
+		return notFound("group with group key %s not found", groupKey)
 	}
 
 	_, ok = fasc.Members[groupKey][memberKey]
 	if !ok {
-		return fmt.Errorf("member with groupKey %s and memberKey %s not found", groupKey, memberKey)
+		return notFound("member with groupKey %s and memberKey %s not found", groupKey, memberKey)
 	}
 
 	delete(fasc.Members[groupKey], memberKey)
 	return nil
 }
 
+func (fasc *FakeAdminServiceClient) InsertGroupAlias(groupKey, alias string) (*admin.Alias, error) {
+	if err := fasc.injectFault("InsertGroupAlias", groupKey); err != nil {
+		return nil, err
+	}
+	fasc.mutex.Lock()
+	defer fasc.mutex.Unlock()
+	group, ok := fasc.Groups[groupKey]
+	if !ok {
+		return nil, notFound("group key %s not found", groupKey)
+	}
+
+	group.Aliases = append(group.Aliases, alias)
+	return &admin.Alias{Alias: alias, PrimaryEmail: groupKey}, nil
+}
+
+func (fasc *FakeAdminServiceClient) DeleteGroupAlias(groupKey, alias string) error {
+	if err := fasc.injectFault("DeleteGroupAlias", groupKey); err != nil {
+		return err
+	}
+	fasc.mutex.Lock()
+	defer fasc.mutex.Unlock()
+	group, ok := fasc.Groups[groupKey]
+	if !ok {
+		return notFound("group key %s not found", groupKey)
+	}
+
+	for i, a := range group.Aliases {
+		if a == alias {
+			group.Aliases = append(group.Aliases[:i:i], group.Aliases[i+1:]...)
+			return nil
+		}
+	}
+	return notFound("alias %s of group key %s not found", alias, groupKey)
+}
+
+func (fasc *FakeAdminServiceClient) GetUser(userKey string) (*admin.User, error) {
+	if err := fasc.injectFault("GetUser", ""); err != nil {
+		return nil, err
+	}
+	fasc.mutex.RLock()
+	defer fasc.mutex.RUnlock()
+	user, ok := fasc.Users[userKey]
+	if !ok {
+		return nil, notFound("user key %s not found", userKey)
+	}
+
+	return user, nil
+}
+
 // FakeGroupServiceClient implements the GroupServiceClient but is fake.
 type FakeGroupServiceClient struct {
 	GsGroups map[string]*groupssettings.Groups
-	mutex    sync.RWMutex
+
+	FaultInjector
+
+	mutex sync.RWMutex
 }
 
 func NewFakeGroupServiceClient() *FakeGroupServiceClient {
@@ -277,24 +406,119 @@ func NewAugmentedFakeGroupServiceClient() *FakeGroupServiceClient {
 }
 
 func (fgsc *FakeGroupServiceClient) Get(groupUniqueID string) (*groupssettings.Groups, error) {
+	if err := fgsc.injectFault("Get", groupUniqueID); err != nil {
+		return nil, err
+	}
 	fgsc.mutex.RLock()
 	defer fgsc.mutex.RUnlock()
 	gsg, ok := fgsc.GsGroups[groupUniqueID]
 	if !ok {
-		return nil, fmt.Errorf("groupUniqueID not found %ss", groupUniqueID)
+		return nil, notFound("groupUniqueID %s not found", groupUniqueID)
 	}
 
 	return gsg, nil
 }
 
 func (fgsc *FakeGroupServiceClient) Patch(groupUniqueID string, groups *groupssettings.Groups) (*groupssettings.Groups, error) {
+	if err := fgsc.injectFault("Patch", groupUniqueID); err != nil {
+		return nil, err
+	}
 	fgsc.mutex.Lock()
 	defer fgsc.mutex.Unlock()
 	_, ok := fgsc.GsGroups[groupUniqueID]
 	if !ok {
-		return nil, fmt.Errorf("groupUniqueID not found %ss", groupUniqueID)
+		return nil, notFound("groupUniqueID %s not found", groupUniqueID)
 	}
 
 	fgsc.GsGroups[groupUniqueID] = groups
 	return groups, nil
 }
+
+// FakeCloudIdentityClient implements the CloudIdentityClient but is fake.
+type FakeCloudIdentityClient struct {
+	// Groups is a mapping from group resource name, groups/{group}, to
+	// *cloudidentity.Group. Groups are looked up by their GroupKey.Id.
+	Groups map[string]*cloudidentity.Group
+
+	FaultInjector
+
+	mutex sync.RWMutex
+}
+
+func NewFakeCloudIdentityClient() *FakeCloudIdentityClient {
+	return &FakeCloudIdentityClient{
+		Groups: make(map[string]*cloudidentity.Group),
+	}
+}
+
+// NewAugmentedFakeCloudIdentityClient returns a FakeCloudIdentityClient with
+// the groups of NewAugmentedFakeAdminServiceClient, with the labels Cloud
+// Identity sets on groups with an email address.
+func NewAugmentedFakeCloudIdentityClient() *FakeCloudIdentityClient {
+	fakeClient := NewFakeCloudIdentityClient()
+	for _, email := range []string{"group1@email.com", "group2@email.com"} {
+		name := "groups/" + strings.TrimSuffix(email, "@email.com")
+		fakeClient.Groups[name] = &cloudidentity.Group{
+			Name:     name,
+			GroupKey: &cloudidentity.EntityKey{Id: email},
+			Labels:   map[string]string{"cloudidentity.googleapis.com/groups.discussion_forum": ""},
+		}
+	}
+
+	return fakeClient
+}
+
+func (fcic *FakeCloudIdentityClient) LookupGroupName(email string) (string, error) {
+	if err := fcic.injectFault("LookupGroupName", email); err != nil {
+		return "", err
+	}
+	fcic.mutex.RLock()
+	defer fcic.mutex.RUnlock()
+	for name, g := range fcic.Groups {
+		if g.GroupKey != nil && strings.EqualFold(g.GroupKey.Id, email) {
+			return name, nil
+		}
+	}
+
+	return "", notFound("group with email %s not found", email)
+}
+
+func (fcic *FakeCloudIdentityClient) GetGroup(name string) (*cloudidentity.Group, error) {
+	if err := fcic.injectFault("GetGroup", fcic.groupKey(name)); err != nil {
+		return nil, err
+	}
+	fcic.mutex.RLock()
+	defer fcic.mutex.RUnlock()
+	g, ok := fcic.Groups[name]
+	if !ok {
+		return nil, notFound("group %s not found", name)
+	}
+
+	return g, nil
+}
+
+func (fcic *FakeCloudIdentityClient) PatchGroupLabels(name string, labels map[string]string) error {
+	if err := fcic.injectFault("PatchGroupLabels", fcic.groupKey(name)); err != nil {
+		return err
+	}
+	fcic.mutex.Lock()
+	defer fcic.mutex.Unlock()
+	g, ok := fcic.Groups[name]
+	if !ok {
+		return notFound("group %s not found", name)
+	}
+
+	g.Labels = labels
+	return nil
+}
+
+// groupKey returns the email of the group with the given name, which faults
+// are matched against, or name if there is no such group.
+func (fcic *FakeCloudIdentityClient) groupKey(name string) string {
+	fcic.mutex.RLock()
+	defer fcic.mutex.RUnlock()
+	if g, ok := fcic.Groups[name]; ok && g.GroupKey != nil {
+		return g.GroupKey.Id
+	}
+	return name
+}
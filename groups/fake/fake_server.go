@@ -0,0 +1,598 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/option"
+)
+
+const (
+	// DefaultPageSize is the page size used by the Directory API when
+	// maxResults is not specified, and the largest page size it allows.
+	DefaultPageSize = 200
+
+	directoryGroupsPath = "/admin/directory/v1/groups"
+	directoryUsersPath  = "/admin/directory/v1/users"
+	settingsGroupsPath  = "/groups/v1/groups"
+)
+
+// FakeServer is an in-process HTTP server emulating the subset of the
+// Admin SDK Directory and Groups Settings REST APIs used by the groups
+// reconciler. Unlike FakeAdminServiceClient and FakeGroupServiceClient it
+// is exercised through the real API clients, so request encoding,
+// pagination and error mapping are covered as well.
+//
+// The Directory API is served under /admin/directory/v1/ and the Groups
+// Settings API both under /groups/v1/groups/ and at the root, so a single
+// option.WithEndpoint works for both clients.
+type FakeServer struct {
+	*httptest.Server
+
+	// PageSize is the maximum number of items returned by a single list
+	// call. If zero, DefaultPageSize is used.
+	PageSize int
+
+	// Groups is a mapping from group email to *admin.Group
+	Groups map[string]*admin.Group
+	// Members is a mapping from group email -> members of that group
+	// Members of a group are a mapping from member email -> *admin.Member
+	Members map[string]map[string]*admin.Member
+	// GsGroups is a mapping from group email to *groupssettings.Groups
+	GsGroups map[string]*groupssettings.Groups
+	// Users is a mapping from user email to *admin.User
+	Users map[string]*admin.User
+
+	nextID int
+	mutex  sync.Mutex
+}
+
+// NewFakeServer starts a FakeServer without any groups. Callers must call
+// Close when done.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		Groups:   make(map[string]*admin.Group),
+		Members:  make(map[string]map[string]*admin.Member),
+		GsGroups: make(map[string]*groupssettings.Groups),
+		Users:    make(map[string]*admin.User),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewAugmentedFakeServer starts a FakeServer seeded with the same groups,
+// members and settings as NewAugmentedFakeAdminServiceClient and
+// NewAugmentedFakeGroupServiceClient.
+func NewAugmentedFakeServer() *FakeServer {
+	s := NewFakeServer()
+	s.Groups = NewAugmentedFakeAdminServiceClient().Groups
+	s.Members = NewAugmentedFakeAdminServiceClient().Members
+	s.GsGroups = NewAugmentedFakeGroupServiceClient().GsGroups
+	s.Users = NewAugmentedFakeAdminServiceClient().Users
+	return s
+}
+
+// ClientOptions returns the options that point the Directory and Groups
+// Settings API clients at this server.
+func (s *FakeServer) ClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(s.URL + "/"),
+		option.WithoutAuthentication(),
+	}
+}
+
+func (s *FakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case strings.HasPrefix(path, directoryGroupsPath):
+		s.serveDirectory(w, r, splitPath(strings.TrimPrefix(path, directoryGroupsPath)))
+	case strings.HasPrefix(path, directoryUsersPath):
+		s.serveUsers(w, r, splitPath(strings.TrimPrefix(path, directoryUsersPath)))
+	case strings.HasPrefix(path, settingsGroupsPath):
+		s.serveSettings(w, r, splitPath(strings.TrimPrefix(path, settingsGroupsPath)))
+	default:
+		s.serveSettings(w, r, splitPath(path))
+	}
+}
+
+// serveDirectory serves the groups and members resources of the Directory API:
+//
+//	/admin/directory/v1/groups
+//	/admin/directory/v1/groups/{groupKey}
+//	/admin/directory/v1/groups/{groupKey}/members
+//	/admin/directory/v1/groups/{groupKey}/members/{memberKey}
+//	/admin/directory/v1/groups/{groupKey}/aliases
+//	/admin/directory/v1/groups/{groupKey}/aliases/{alias}
+func (s *FakeServer) serveDirectory(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.listGroups(w, r)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.insertGroup(w, r)
+	case len(parts) == 1:
+		s.serveGroup(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "members" && r.Method == http.MethodGet:
+		s.listMembers(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "members" && r.Method == http.MethodPost:
+		s.insertMember(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "members":
+		s.serveMember(w, r, parts[0], parts[2])
+	case len(parts) == 2 && parts[1] == "aliases" && r.Method == http.MethodPost:
+		s.insertAlias(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "aliases" && r.Method == http.MethodDelete:
+		s.deleteAlias(w, parts[0], parts[2])
+	default:
+		writeError(w, http.StatusNotFound, "notFound", fmt.Sprintf("%s %s is not supported", r.Method, r.URL.Path))
+	}
+}
+
+// serveUsers serves the read-only users resource of the Directory API:
+//
+//	/admin/directory/v1/users/{userKey}
+func (s *FakeServer) serveUsers(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) != 1 || r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "notFound", fmt.Sprintf("%s %s is not supported", r.Method, r.URL.Path))
+		return
+	}
+	for email, u := range s.Users {
+		if strings.EqualFold(email, parts[0]) || (u.Id != "" && u.Id == parts[0]) {
+			writeJSON(w, http.StatusOK, u)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: userKey")
+}
+
+func (s *FakeServer) listGroups(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("customer") == "" && r.URL.Query().Get("domain") == "" {
+		writeError(w, http.StatusBadRequest, "badRequest", "Bad Request")
+		return
+	}
+
+	domain := r.URL.Query().Get("domain")
+	query := r.URL.Query().Get("query")
+	emails := make([]string, 0, len(s.Groups))
+	for email, g := range s.Groups {
+		if domain != "" && !strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(domain)) {
+			continue
+		}
+		if query != "" && !matchesQuery(g, query) {
+			continue
+		}
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	start, end, next, ok := s.page(w, r, len(emails))
+	if !ok {
+		return
+	}
+	resp := &admin.Groups{Kind: "admin#directory#groups", NextPageToken: next}
+	for _, email := range emails[start:end] {
+		resp.Groups = append(resp.Groups, s.Groups[email])
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *FakeServer) insertGroup(w http.ResponseWriter, r *http.Request) {
+	var group admin.Group
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid", err.Error())
+		return
+	}
+	if group.Email == "" {
+		writeError(w, http.StatusBadRequest, "required", "Missing required field: email")
+		return
+	}
+	if key, _ := s.groupKey(group.Email); key != "" {
+		writeError(w, http.StatusConflict, "duplicate", "Entity already exists.")
+		return
+	}
+
+	group.Kind = "admin#directory#group"
+	group.Id = s.newID()
+	s.Groups[group.Email] = &group
+	s.Members[group.Email] = map[string]*admin.Member{}
+	s.GsGroups[group.Email] = &groupssettings.Groups{}
+	writeJSON(w, http.StatusOK, &group)
+}
+
+func (s *FakeServer) serveGroup(w http.ResponseWriter, r *http.Request, groupKey string) {
+	key, ok := s.groupKey(groupKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Groups[key])
+	case http.MethodPut, http.MethodPatch:
+		var group admin.Group
+		if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+		if r.Method == http.MethodPatch {
+			merged := *s.Groups[key]
+			if group.Name != "" {
+				merged.Name = group.Name
+			}
+			if group.Description != "" {
+				merged.Description = group.Description
+			}
+			group = merged
+		}
+		group.Kind = s.Groups[key].Kind
+		group.Id = s.Groups[key].Id
+		group.Aliases = s.Groups[key].Aliases
+		if group.Email == "" || strings.EqualFold(group.Email, key) {
+			group.Email = key
+			s.Groups[key] = &group
+			writeJSON(w, http.StatusOK, &group)
+			return
+		}
+
+		// Changing the email of a group keeps the previous one as an alias.
+		if other, _ := s.groupKey(group.Email); other != "" && other != key {
+			writeError(w, http.StatusConflict, "duplicate", "Entity already exists.")
+			return
+		}
+		group.Aliases = append(append([]string{}, group.Aliases...), key)
+		delete(s.Groups, key)
+		s.Groups[group.Email] = &group
+		s.Members[group.Email] = s.Members[key]
+		delete(s.Members, key)
+		s.GsGroups[group.Email] = s.GsGroups[key]
+		delete(s.GsGroups, key)
+		writeJSON(w, http.StatusOK, &group)
+	case http.MethodDelete:
+		delete(s.Groups, key)
+		delete(s.Members, key)
+		delete(s.GsGroups, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "badRequest", "Method Not Allowed")
+	}
+}
+
+func (s *FakeServer) insertAlias(w http.ResponseWriter, r *http.Request, groupKey string) {
+	key, ok := s.groupKey(groupKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+		return
+	}
+	var alias admin.Alias
+	if err := json.NewDecoder(r.Body).Decode(&alias); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid", err.Error())
+		return
+	}
+	if alias.Alias == "" {
+		writeError(w, http.StatusBadRequest, "required", "Missing required field: alias")
+		return
+	}
+	for email, g := range s.Groups {
+		if strings.EqualFold(email, alias.Alias) {
+			writeError(w, http.StatusConflict, "duplicate", "Entity already exists.")
+			return
+		}
+		for _, a := range g.Aliases {
+			if strings.EqualFold(a, alias.Alias) {
+				writeError(w, http.StatusConflict, "duplicate", "Entity already exists.")
+				return
+			}
+		}
+	}
+
+	s.Groups[key].Aliases = append(s.Groups[key].Aliases, alias.Alias)
+	alias.Kind = "admin#directory#alias"
+	alias.PrimaryEmail = key
+	writeJSON(w, http.StatusOK, &alias)
+}
+
+func (s *FakeServer) deleteAlias(w http.ResponseWriter, groupKey, alias string) {
+	key, ok := s.groupKey(groupKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+		return
+	}
+	aliases := s.Groups[key].Aliases
+	for i, a := range aliases {
+		if strings.EqualFold(a, alias) {
+			s.Groups[key].Aliases = append(aliases[:i:i], aliases[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: alias")
+}
+
+func (s *FakeServer) listMembers(w http.ResponseWriter, r *http.Request, groupKey string) {
+	key, ok := s.groupKey(groupKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+		return
+	}
+
+	emails := make([]string, 0, len(s.Members[key]))
+	for email := range s.Members[key] {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	start, end, next, ok := s.page(w, r, len(emails))
+	if !ok {
+		return
+	}
+	resp := &admin.Members{Kind: "admin#directory#members", NextPageToken: next}
+	for _, email := range emails[start:end] {
+		resp.Members = append(resp.Members, s.Members[key][email])
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *FakeServer) insertMember(w http.ResponseWriter, r *http.Request, groupKey string) {
+	key, ok := s.groupKey(groupKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+		return
+	}
+
+	var member admin.Member
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid", err.Error())
+		return
+	}
+	if member.Email == "" {
+		writeError(w, http.StatusBadRequest, "required", "Missing required field: memberKey")
+		return
+	}
+	if _, ok := s.memberKey(key, member.Email); ok {
+		writeError(w, http.StatusConflict, "duplicate", "Member already exists.")
+		return
+	}
+
+	member.Kind = "admin#directory#member"
+	member.Id = s.newID()
+	if member.Role == "" {
+		member.Role = "MEMBER"
+	}
+	if member.Status == "" {
+		member.Status = "ACTIVE"
+	}
+	if member.DeliverySettings == "" {
+		member.DeliverySettings = "ALL_MAIL"
+	}
+	member.Type = "USER"
+	if _, ok := s.groupKey(member.Email); ok {
+		member.Type = "GROUP"
+	}
+	s.Members[key][member.Email] = &member
+	writeJSON(w, http.StatusOK, &member)
+}
+
+func (s *FakeServer) serveMember(w http.ResponseWriter, r *http.Request, groupKey, memberKey string) {
+	key, ok := s.groupKey(groupKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+		return
+	}
+	email, ok := s.memberKey(key, memberKey)
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: memberKey")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Members[key][email])
+	case http.MethodPut, http.MethodPatch:
+		var member admin.Member
+		if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+		existing := s.Members[key][email]
+		if member.Role != "" {
+			existing.Role = member.Role
+		}
+		if member.DeliverySettings != "" {
+			existing.DeliverySettings = member.DeliverySettings
+		}
+		writeJSON(w, http.StatusOK, existing)
+	case http.MethodDelete:
+		delete(s.Members[key], email)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "badRequest", "Method Not Allowed")
+	}
+}
+
+// serveSettings serves the groups resource of the Groups Settings API:
+//
+//	/groups/v1/groups/{groupUniqueId}
+func (s *FakeServer) serveSettings(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) != 1 {
+		writeError(w, http.StatusNotFound, "notFound", fmt.Sprintf("%s %s is not supported", r.Method, r.URL.Path))
+		return
+	}
+	key, ok := s.groupKey(parts[0])
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupUniqueId")
+		return
+	}
+	settings, ok := s.GsGroups[key]
+	if !ok {
+		settings = &groupssettings.Groups{}
+		s.GsGroups[key] = settings
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, settings)
+	case http.MethodPatch, http.MethodPut:
+		// Fields omitted from the request are left unchanged, which is
+		// what decoding on top of the existing settings does.
+		if err := json.NewDecoder(r.Body).Decode(settings); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, settings)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "badRequest", "Method Not Allowed")
+	}
+}
+
+// page returns the bounds of the requested page of n items and the token of
+// the next page, or writes an error and returns false if the pageToken or
+// maxResults parameters are invalid.
+func (s *FakeServer) page(w http.ResponseWriter, r *http.Request, n int) (int, int, string, bool) {
+	size := s.PageSize
+	if size <= 0 || size > DefaultPageSize {
+		size = DefaultPageSize
+	}
+	if v := r.URL.Query().Get("maxResults"); v != "" {
+		maxResults, err := strconv.Atoi(v)
+		if err != nil || maxResults < 1 {
+			writeError(w, http.StatusBadRequest, "invalid", "Invalid value for: maxResults")
+			return 0, 0, "", false
+		}
+		if maxResults < size {
+			size = maxResults
+		}
+	}
+
+	start := 0
+	if v := r.URL.Query().Get("pageToken"); v != "" {
+		var err error
+		start, err = strconv.Atoi(v)
+		if err != nil || start < 0 || start > n {
+			writeError(w, http.StatusBadRequest, "invalid", "Invalid value for: pageToken")
+			return 0, 0, "", false
+		}
+	}
+
+	end := start + size
+	if end >= n {
+		return start, n, "", true
+	}
+	return start, end, strconv.Itoa(end), true
+}
+
+// groupKey resolves a group email, alias or id to the key used in Groups.
+func (s *FakeServer) groupKey(groupKey string) (string, bool) {
+	for email, g := range s.Groups {
+		if strings.EqualFold(email, groupKey) || (g.Id != "" && g.Id == groupKey) {
+			return email, true
+		}
+		for _, alias := range g.Aliases {
+			if strings.EqualFold(alias, groupKey) {
+				return email, true
+			}
+		}
+	}
+	return "", false
+}
+
+// memberKey resolves a member email or id to the key used in Members.
+func (s *FakeServer) memberKey(groupKey, memberKey string) (string, bool) {
+	for email, m := range s.Members[groupKey] {
+		if strings.EqualFold(email, memberKey) || (m.Id != "" && m.Id == memberKey) {
+			return email, true
+		}
+	}
+	return "", false
+}
+
+func (s *FakeServer) newID() string {
+	s.nextID++
+	return fmt.Sprintf("%021d", s.nextID)
+}
+
+// matchesQuery reports whether g matches a Directory API groups search
+// query. Only space separated email:, name: and description: clauses are
+// supported, with an optional trailing * for a prefix match.
+func matchesQuery(g *admin.Group, query string) bool {
+	for _, clause := range strings.Fields(query) {
+		field, value, ok := strings.Cut(clause, ":")
+		if !ok {
+			return false
+		}
+		var actual string
+		switch field {
+		case "email":
+			actual = g.Email
+		case "name":
+			actual = g.Name
+		case "description":
+			actual = g.Description
+		default:
+			return false
+		}
+		actual = strings.ToLower(actual)
+		value = strings.ToLower(strings.Trim(value, "'\""))
+		if prefix, ok := strings.CutSuffix(value, "*"); ok {
+			if !strings.HasPrefix(actual, prefix) {
+				return false
+			}
+		} else if actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format used by Google APIs, which the
+// API clients decode into a *googleapi.Error.
+func writeError(w http.ResponseWriter, code int, reason, message string) {
+	writeJSON(w, code, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+			"errors": []map[string]string{
+				{"domain": "global", "reason": reason, "message": message},
+			},
+		},
+	})
+}
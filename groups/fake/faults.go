@@ -0,0 +1,119 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Fault describes an error returned by the calls of a fake client that it
+// matches. The zero value of a field matches any call.
+type Fault struct {
+	// Method is the name of the client method to fail, e.g. "InsertMember".
+	Method string
+	// GroupKey restricts the fault to calls for this group.
+	GroupKey string
+	// Every fails only every Nth matching call, e.g. 2 fails the 2nd, 4th, ...
+	// matching calls. Zero or one fails every matching call.
+	Every int
+	// Code is the HTTP status code of the returned *googleapi.Error, e.g.
+	// http.StatusNotFound, http.StatusConflict, http.StatusTooManyRequests or
+	// http.StatusInternalServerError. Defaults to http.StatusInternalServerError.
+	Code int
+
+	calls int
+}
+
+// FaultInjector injects faults and latency into the calls of a fake client.
+// It is embedded in FakeAdminServiceClient, FakeGroupServiceClient and
+// FakeCloudIdentityClient, so faults can be configured directly on those.
+type FaultInjector struct {
+	faults  []*Fault
+	latency time.Duration
+	mutex   sync.Mutex
+}
+
+// AddFault registers a fault for all subsequent calls.
+func (fi *FaultInjector) AddFault(f Fault) {
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+	fi.faults = append(fi.faults, &f)
+}
+
+// SetLatency delays every subsequent call by d.
+func (fi *FaultInjector) SetLatency(d time.Duration) {
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+	fi.latency = d
+}
+
+// ClearFaults removes all faults and latency.
+func (fi *FaultInjector) ClearFaults() {
+	fi.mutex.Lock()
+	defer fi.mutex.Unlock()
+	fi.faults = nil
+	fi.latency = 0
+}
+
+// injectFault sleeps for the configured latency and returns the error of the
+// first fault matching a call to method for groupKey, if any.
+func (fi *FaultInjector) injectFault(method, groupKey string) error {
+	fi.mutex.Lock()
+	latency := fi.latency
+	var err error
+	for _, f := range fi.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if f.GroupKey != "" && !strings.EqualFold(f.GroupKey, groupKey) {
+			continue
+		}
+		f.calls++
+		if f.Every > 1 && f.calls%f.Every != 0 {
+			continue
+		}
+		code := f.Code
+		if code == 0 {
+			code = http.StatusInternalServerError
+		}
+		err = &googleapi.Error{
+			Code:    code,
+			Message: fmt.Sprintf("injected fault: %s %s: %s", method, groupKey, http.StatusText(code)),
+		}
+		break
+	}
+	fi.mutex.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	return err
+}
+
+// notFound returns the error the Google APIs return for a missing resource.
+func notFound(format string, a ...interface{}) error {
+	return &googleapi.Error{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
@@ -0,0 +1,392 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// plainEmailRe matches addresses that can be written in YAML without quotes.
+var plainEmailRe = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+$`)
+
+// nestedGroupPrefix is the prefix of the k8s-infra groups which, when nested
+// in another group, are conventionally listed before its other members.
+const nestedGroupPrefix = "k8s-infra-"
+
+// lineEdit replaces the lines start to end (1-based, inclusive) of a file.
+type lineEdit struct {
+	start, end int
+	lines      []string
+}
+
+// formatGroupsFile returns content in the canonical form of a groups.yaml
+// file. Within each group:
+//
+//   - the addresses of owners, managers and members are lowercased, sorted
+//     and exact duplicates are dropped, with nested k8s-infra groups sorted
+//     before the other addresses, see lessAddress;
+//   - settings are sorted by name;
+//   - list items and settings are indented by two spaces more than the field
+//     they belong to.
+//
+// Comments at the end of a line stay with their item. A comment on a line of
+// its own within a list starts a new section: items are sorted within each
+// section, so that comments like "# emeritus" keep describing the items
+// following them. Blank lines within lists and settings are dropped. The
+// rest of the file, including comments and blank lines between groups, is
+// left untouched.
+//
+// Lists using flow style or items that are not single line strings are left
+// as they are.
+func formatGroupsFile(content []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return content, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var edits []lineEdit
+	if groups := mappingValue(doc.Content[0], "groups"); groups != nil && groups.Kind == yaml.SequenceNode {
+		for _, group := range groups.Content {
+			if group.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(group.Content); i += 2 {
+				key, value := group.Content[i], group.Content[i+1]
+				var (
+					edit lineEdit
+					ok   bool
+				)
+				switch key.Value {
+				case "owners", "managers", "members":
+					edit, ok = formatMemberList(lines, key, value)
+				case "settings":
+					edit, ok = formatSettings(lines, key, value)
+				}
+				if ok {
+					edits = append(edits, edit)
+				}
+			}
+		}
+	}
+
+	// Apply the edits from the bottom up, so that line numbers of the edits
+	// left to apply stay valid.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		tail := append([]string{}, lines[e.end:]...)
+		lines = append(append(lines[:e.start-1], e.lines...), tail...)
+	}
+	res := []byte(strings.Join(lines, "\n"))
+
+	// Formatting must not change the meaning of the file beyond what it is
+	// meant to canonicalize.
+	var before, after GroupsConfig
+	if err := yaml.Unmarshal(content, &before); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(res, &after); err != nil {
+		return nil, fmt.Errorf("formatting produced invalid YAML: %w", err)
+	}
+	if !reflect.DeepEqual(canonicalGroups(before.Groups, strings.ToLower), canonicalGroups(after.Groups, strings.ToLower)) {
+		return nil, fmt.Errorf("formatting changed the groups defined")
+	}
+	return res, nil
+}
+
+// canonicalGroups returns a copy of groups with the addresses of each role
+// replaced by their canonical form, sorted and deduplicated. With
+// strings.ToLower, this is what formatGroupsFile does.
+func canonicalGroups(groups []GoogleGroup, canonical func(string) string) []GoogleGroup {
+	res := make([]GoogleGroup, len(groups))
+	for i, g := range groups {
+		g.source = groupSource{}
+		for _, list := range []*[]string{&g.Owners, &g.Managers, &g.Members} {
+			seen := map[string]bool{}
+			var addresses []string
+			for _, m := range *list {
+				m = canonical(m)
+				if !seen[m] {
+					seen[m] = true
+					addresses = append(addresses, m)
+				}
+			}
+			sort.Strings(addresses)
+			*list = addresses
+		}
+		res[i] = g
+	}
+	return res
+}
+
+// formatMemberList returns the edit formatting the list of addresses value,
+// the value of key, or false if the list is left as is.
+func formatMemberList(lines []string, key, value *yaml.Node) (lineEdit, bool) {
+	if value.Kind != yaml.SequenceNode || len(value.Content) == 0 || value.Style&yaml.FlowStyle != 0 {
+		return lineEdit{}, false
+	}
+	items := map[int]*yaml.Node{}
+	for _, item := range value.Content {
+		if item.Kind != yaml.ScalarNode || item.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return lineEdit{}, false
+		}
+		if _, ok := items[item.Line]; ok {
+			return lineEdit{}, false
+		}
+		items[item.Line] = item
+	}
+
+	type entry struct{ email, comment string }
+	indent := strings.Repeat(" ", key.Column+1)
+	edit := lineEdit{start: value.Content[0].Line, end: value.Content[len(value.Content)-1].Line}
+	seen := map[string]bool{}
+	var section []entry
+	flush := func() {
+		sort.SliceStable(section, func(i, j int) bool { return lessAddress(section[i].email, section[j].email) })
+		for _, e := range section {
+			line := indent + "- " + e.email
+			if e.comment != "" {
+				line += " " + e.comment
+			}
+			edit.lines = append(edit.lines, line)
+		}
+		section = nil
+	}
+	for n := edit.start; n <= edit.end; n++ {
+		text := strings.TrimSpace(lines[n-1])
+		item, ok := items[n]
+		switch {
+		case ok:
+			email := strings.ToLower(item.Value)
+			if !plainEmailRe.MatchString(email) {
+				return lineEdit{}, false
+			}
+			if seen[email] {
+				continue
+			}
+			seen[email] = true
+			section = append(section, entry{email: email, comment: item.LineComment})
+		case text == "":
+		case strings.HasPrefix(text, "#"):
+			flush()
+			edit.lines = append(edit.lines, indent+text)
+		default:
+			return lineEdit{}, false
+		}
+	}
+	flush()
+	return edit, true
+}
+
+// lessAddress reports whether the lowercased address a sorts before b in a
+// list of addresses: nested k8s-infra groups come first, then the others, in
+// lexical order.
+func lessAddress(a, b string) bool {
+	aNested, bNested := strings.HasPrefix(a, nestedGroupPrefix), strings.HasPrefix(b, nestedGroupPrefix)
+	if aNested != bNested {
+		return aNested
+	}
+	return a < b
+}
+
+// formatSettings returns the edit sorting the settings value, the value of
+// key, by name or false if the settings are left as is. Comments on a line
+// of their own move along with the setting following them.
+func formatSettings(lines []string, key, value *yaml.Node) (lineEdit, bool) {
+	if value.Kind != yaml.MappingNode || len(value.Content) == 0 || value.Style&yaml.FlowStyle != 0 {
+		return lineEdit{}, false
+	}
+	settings := map[int]*yaml.Node{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		if v.Kind != yaml.ScalarNode || v.Line != k.Line || v.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return lineEdit{}, false
+		}
+		settings[k.Line] = k
+	}
+
+	type entry struct {
+		name  string
+		lines []string
+	}
+	indent := strings.Repeat(" ", key.Column+1)
+	edit := lineEdit{start: value.Content[0].Line, end: value.Content[len(value.Content)-1].Line}
+	var (
+		entries  []entry
+		comments []string
+	)
+	for n := edit.start; n <= edit.end; n++ {
+		text := strings.TrimSpace(lines[n-1])
+		k, ok := settings[n]
+		switch {
+		case ok:
+			entries = append(entries, entry{name: k.Value, lines: append(comments, indent+text)})
+			comments = nil
+		case text == "":
+		case strings.HasPrefix(text, "#"):
+			comments = append(comments, indent+text)
+		default:
+			return lineEdit{}, false
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	for _, e := range entries {
+		edit.lines = append(edit.lines, e.lines...)
+	}
+	return edit, true
+}
+
+// groupsFiles returns the groups.yaml files in paths, which are files or
+// directories searched recursively, without duplicates.
+func groupsFiles(paths []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && d.Name() == "groups.yaml" {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// runFmt implements the fmt command: it formats the groups.yaml files found
+// in args, or in the groups-path of each tenant of the config, in place.
+// With -check, it only lists the files that are not formatted and fails if
+// there are any.
+func runFmt(o *options, args []string) error {
+	flags := newFlagSet("fmt", o, false)
+	check := flags.Bool("check", false, "list files that are not formatted instead of formatting them, and fail if there are any")
+	changedSince := flags.String("changed-since", "", "only format the files that changed since this git revision")
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		if err := config.Load(o.configFile, false); err != nil {
+			return err
+		}
+		for _, t := range config.Tenants {
+			paths = append(paths, t.GroupsPath)
+		}
+	}
+	files, err := groupsFiles(paths)
+	if err != nil {
+		return err
+	}
+	if *changedSince != "" {
+		if files, err = filesChangedSince(*changedSince, files); err != nil {
+			return err
+		}
+	}
+
+	var unformatted []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		formatted, err := formatGroupsFile(content)
+		if err != nil {
+			return fmt.Errorf("error formatting %s: %w", file, err)
+		}
+		if bytes.Equal(content, formatted) {
+			continue
+		}
+		unformatted = append(unformatted, file)
+		if *check {
+			fmt.Println(file)
+			continue
+		}
+		if err := os.WriteFile(file, formatted, 0644); err != nil {
+			return err
+		}
+	}
+	if *check && len(unformatted) > 0 {
+		return fmt.Errorf("%d files are not formatted, run `make fmt` to format them", len(unformatted))
+	}
+	return nil
+}
+
+// filesChangedSince returns the files that differ from their content at the
+// git revision rev, including those that aren't committed yet, so that only
+// the files touched by a change are checked.
+func filesChangedSince(rev string, files []string) ([]string, error) {
+	top, err := git(".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git(".", "diff", "--name-only", "-z", rev, "--")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the files changed since %s: %w", rev, err)
+	}
+	untracked, err := git(".", "ls-files", "--others", "--exclude-standard", "--full-name", "-z")
+	if err != nil {
+		return nil, err
+	}
+	changed := map[string]bool{}
+	for _, name := range strings.Split(string(diff)+string(untracked), "\x00") {
+		if name != "" {
+			changed[filepath.Join(strings.TrimSpace(string(top)), name)] = true
+		}
+	}
+	var res []string
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		if changed[abs] {
+			res = append(res, file)
+		}
+	}
+	return res, nil
+}
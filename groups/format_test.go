@@ -0,0 +1,204 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestFormatGroupsFile(t *testing.T) {
+	cases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc: "already formatted",
+			input: `groups:
+  - email-id: group1@example.com
+    owners:
+      - a@example.com
+      - b@example.com
+`,
+		},
+		{
+			desc: "sort, lowercase and dedupe members of each role",
+			input: `groups:
+  - email-id: group1@example.com
+    owners:
+      - c@example.com
+      - B@Example.com
+    managers:
+      - z@example.com
+    members:
+      - y@example.com
+      - x@example.com
+      - y@example.com
+`,
+			expected: `groups:
+  - email-id: group1@example.com
+    owners:
+      - b@example.com
+      - c@example.com
+    managers:
+      - z@example.com
+    members:
+      - x@example.com
+      - y@example.com
+`,
+		},
+		{
+			desc: "nested k8s-infra groups first",
+			input: `groups:
+  - email-id: k8s-infra-group1@example.com
+    members:
+      - jane@example.com
+      - k8s-infra-rbac-foo@example.com
+      - sig-foo-leads@example.com
+      - k8s-infra-foo-admins@example.com
+`,
+			expected: `groups:
+  - email-id: k8s-infra-group1@example.com
+    members:
+      - k8s-infra-foo-admins@example.com
+      - k8s-infra-rbac-foo@example.com
+      - jane@example.com
+      - sig-foo-leads@example.com
+`,
+		},
+		{
+			desc: "comments and indentation",
+			input: `# header
+groups:
+  # first group
+  - email-id: group1@example.com
+    members:
+    - c@example.com # SIG ContribEx
+    - "b@example.com"
+
+    - a@example.com
+       # emeritus
+    - z@example.com
+    - d@example.com
+    # section comment after the list
+
+  - email-id: group2@example.com
+`,
+			expected: `# header
+groups:
+  # first group
+  - email-id: group1@example.com
+    members:
+      - a@example.com
+      - b@example.com
+      - c@example.com # SIG ContribEx
+      # emeritus
+      - d@example.com
+      - z@example.com
+    # section comment after the list
+
+  - email-id: group2@example.com
+`,
+		},
+		{
+			desc: "settings are sorted by name",
+			input: `groups:
+  - email-id: group1@example.com
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST"
+      # keep the group in sync
+      ReconcileMembers: "true"
+      AllowWebPosting: "true" # for the forum
+    members:
+      - a@example.com
+`,
+			expected: `groups:
+  - email-id: group1@example.com
+    settings:
+      AllowWebPosting: "true" # for the forum
+      # keep the group in sync
+      ReconcileMembers: "true"
+      WhoCanPostMessage: "ANYONE_CAN_POST"
+    members:
+      - a@example.com
+`,
+		},
+		{
+			desc: "flow lists are left as is",
+			input: `groups:
+  - email-id: group1@example.com
+    members: [b@example.com, a@example.com]
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			expected := c.expected
+			if expected == "" {
+				expected = c.input
+			}
+			actual, err := formatGroupsFile([]byte(c.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+			again, err := formatGroupsFile(actual)
+			if err != nil {
+				t.Fatalf("unexpected error formatting again: %v", err)
+			}
+			if !bytes.Equal(actual, again) {
+				t.Errorf("formatting is not idempotent, formatting again gave:\n%s", again)
+			}
+		})
+	}
+}
+
+// TestGroupsFilesFormatted ensures the groups.yaml files changed since
+// PULL_BASE_SHA, the base of the pull request being tested, are in canonical
+// form, so that files are formatted as they are edited.
+func TestGroupsFilesFormatted(t *testing.T) {
+	base := os.Getenv("PULL_BASE_SHA")
+	if base == "" {
+		t.Skip("PULL_BASE_SHA is not set, run `make fmt` to format the files you changed")
+	}
+	files, err := groupsFiles([]string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files, err = filesChangedSince(base, files); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		formatted, err := formatGroupsFile(content)
+		if err != nil {
+			t.Errorf("error formatting %s: %v", file, err)
+			continue
+		}
+		if !bytes.Equal(content, formatted) {
+			t.Errorf("%s is not formatted, run `make fmt` to format it", file)
+		}
+	}
+}
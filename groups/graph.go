@@ -0,0 +1,317 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Formats of the export-graph command.
+const (
+	DOTGraphFormat     = "dot"
+	MermaidGraphFormat = "mermaid"
+	JSONGraphFormat    = "json"
+)
+
+// Kinds of the nodes of a Graph.
+const (
+	GroupNodeKind  = "group"
+	PersonNodeKind = "person"
+)
+
+// Graph is the nesting and membership of groups: its edges go from each
+// owner, manager and member of a group, nested groups included, to the
+// group.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a group or a person of a Graph.
+type GraphNode struct {
+	// ID is the lowercased email of the group or person.
+	ID string `json:"id"`
+	// Kind is GroupNodeKind or PersonNodeKind.
+	Kind string `json:"kind"`
+	// Path is the groups.yaml file defining the group, relative to the
+	// groups-path, if it is defined in one.
+	Path string `json:"path,omitempty"`
+}
+
+// GraphEdge is a role of a group or person, From, in the group To.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Role string `json:"role"`
+}
+
+// graphOptions holds the flags of the export-graph command, and the groups
+// collected from each tenant.
+type graphOptions struct {
+	format string
+	// paths are doublestar globs of the groups.yaml files, relative to the
+	// groups-path, whose groups are exported.
+	paths stringList
+	// prefixes are prefixes of the emails of the groups exported.
+	prefixes stringList
+	// groupsOnly leaves the people out, keeping the nesting of groups.
+	groupsOnly bool
+
+	// groups are the exported groups of every tenant, with the path of
+	// the groups.yaml file defining them.
+	groups []graphGroup
+	// isGroup has the lowercased emails of every group, exported or not,
+	// so that nested groups are told apart from people.
+	isGroup map[string]bool
+}
+
+// graphGroup is a group of a Graph, with the path of the groups.yaml file
+// defining it, if any.
+type graphGroup struct {
+	GoogleGroup
+	path string
+}
+
+// selects reports whether the group g, defined in the groups.yaml file at
+// path relative to the groups-path, is exported: if it matches any path or
+// prefix, or any group if there are neither.
+func (o *graphOptions) selects(g GoogleGroup, path string) bool {
+	if len(o.paths) == 0 && len(o.prefixes) == 0 {
+		return true
+	}
+	if path != "" && matchesGlob(path, o.paths) {
+		return true
+	}
+	for _, prefix := range o.prefixes {
+		if strings.HasPrefix(strings.ToLower(g.EmailId), strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addGroup records that g, defined at path, is a group, and exports it if
+// it is selected.
+func (o *graphOptions) addGroup(g GoogleGroup, path string) {
+	if o.isGroup == nil {
+		o.isGroup = map[string]bool{}
+	}
+	o.isGroup[strings.ToLower(g.EmailId)] = true
+	if o.selects(g, path) {
+		o.groups = append(o.groups, graphGroup{GoogleGroup: g, path: path})
+	}
+}
+
+// graph returns the graph of the exported groups. Members are groups if
+// they are one of the groups added, or else people.
+func (o *graphOptions) graph() Graph {
+	var g Graph
+	nodes := map[string]int{}
+	addNode := func(n GraphNode) {
+		if i, ok := nodes[n.ID]; ok {
+			if g.Nodes[i].Path == "" {
+				g.Nodes[i].Path = n.Path
+			}
+			return
+		}
+		nodes[n.ID] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, n)
+	}
+	for _, group := range o.groups {
+		to := strings.ToLower(group.EmailId)
+		addNode(GraphNode{ID: to, Kind: GroupNodeKind, Path: group.path})
+		for _, list := range []struct {
+			role    string
+			members []string
+		}{
+			{OwnerRole, group.Owners},
+			{ManagerRole, group.Managers},
+			{MemberRole, group.Members},
+		} {
+			for _, m := range list.members {
+				from := strings.ToLower(m)
+				kind := PersonNodeKind
+				if o.isGroup[from] {
+					kind = GroupNodeKind
+				} else if o.groupsOnly {
+					continue
+				}
+				addNode(GraphNode{ID: from, Kind: kind})
+				g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Role: list.role})
+			}
+		}
+	}
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Kind != g.Nodes[j].Kind {
+			return g.Nodes[i].Kind == GroupNodeKind
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].To != g.Edges[j].To {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].From < g.Edges[j].From
+	})
+	return g
+}
+
+// printGraph prints g to w in the given format.
+func printGraph(w io.Writer, g Graph, format string) error {
+	switch format {
+	case DOTGraphFormat:
+		fmt.Fprintln(w, "digraph groups {")
+		fmt.Fprintln(w, "  rankdir=LR;")
+		for _, n := range g.Nodes {
+			shape := "ellipse"
+			if n.Kind == GroupNodeKind {
+				shape = "box"
+			}
+			fmt.Fprintf(w, "  %q [shape=%s];\n", n.ID, shape)
+		}
+		for _, e := range g.Edges {
+			fmt.Fprintf(w, "  %q -> %q [label=%q];\n", e.From, e.To, e.Role)
+		}
+		fmt.Fprintln(w, "}")
+	case MermaidGraphFormat:
+		// Mermaid ids can't contain the characters of an email, so nodes
+		// are numbered and labeled with their email.
+		ids := map[string]string{}
+		fmt.Fprintln(w, "flowchart LR")
+		for i, n := range g.Nodes {
+			ids[n.ID] = fmt.Sprintf("n%d", i)
+			start, end := "([", "])"
+			if n.Kind == GroupNodeKind {
+				start, end = "[", "]"
+			}
+			fmt.Fprintf(w, "  %s%s\"%s\"%s\n", ids[n.ID], start, n.ID, end)
+		}
+		for _, e := range g.Edges {
+			fmt.Fprintf(w, "  %s -- %s --> %s\n", ids[e.From], e.Role, ids[e.To])
+		}
+	case JSONGraphFormat:
+		if g.Nodes == nil {
+			g.Nodes = []GraphNode{}
+		}
+		if g.Edges == nil {
+			g.Edges = []GraphEdge{}
+		}
+		b, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	default:
+		return fmt.Errorf("unknown graph format %q, must be one of %s, %s or %s", format, DOTGraphFormat, MermaidGraphFormat, JSONGraphFormat)
+	}
+	return nil
+}
+
+// addLiveGroups adds the existing groups of the tenant being reconciled to
+// o, with their owners, managers and members as listed by the Admin
+// Directory API. Existing groups are matched to the groups.yaml files by
+// email id, for their path, and members of type GROUP are nested groups.
+func (r *Reconciler) addLiveGroups(o *graphOptions) error {
+	live, err := r.adminService.ListGroups()
+	if err != nil {
+		return fmt.Errorf("unable to list groups: %w", err)
+	}
+	if o.isGroup == nil {
+		o.isGroup = map[string]bool{}
+	}
+	var errs []error
+	for _, lg := range live.Groups {
+		g := GoogleGroup{EmailId: lg.Email}
+		var path string
+		for _, configured := range groupsConfig.Groups {
+			if configured.HasEmail(lg.Email) {
+				path = groupsFilePath(configured, config.GroupsPath)
+				break
+			}
+		}
+		o.isGroup[strings.ToLower(g.EmailId)] = true
+		if !o.selects(g, path) {
+			continue
+		}
+		members, err := r.adminService.ListMembers(lg.Email)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to retrieve members in group %s: %w", lg.Email, err))
+			continue
+		}
+		for _, m := range members {
+			switch m.Role {
+			case OwnerRole:
+				g.Owners = append(g.Owners, m.Email)
+			case ManagerRole:
+				g.Managers = append(g.Managers, m.Email)
+			case MemberRole:
+				g.Members = append(g.Members, m.Email)
+			}
+			if m.Type != "" && m.Type != UserMemberType {
+				o.isGroup[strings.ToLower(m.Email)] = true
+			}
+		}
+		o.groups = append(o.groups, graphGroup{GoogleGroup: g, path: path})
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// runExportGraph implements the export-graph command: it prints the graph of
+// the groups of every tenant of the config, from the groups.yaml files or,
+// with -live, from the existing groups.
+func runExportGraph(o *options, args []string) error {
+	fs := newFlagSet("export-graph", o, true)
+	fs.StringVar(&o.graph.format, "format", DOTGraphFormat, "the format of the graph: dot, mermaid or json")
+	fs.Var(&o.graph.paths, "path", "only export the groups of the groups.yaml files matching this glob, relative to the groups-path, can be repeated")
+	fs.Var(&o.graph.prefixes, "prefix", "only export the groups whose email starts with this prefix, can be repeated")
+	fs.BoolVar(&o.graph.groupsOnly, "groups-only", false, "only export the nesting of groups, without the people in them")
+	live := fs.Bool("live", false, "export the existing groups rather than the groups.yaml files, which needs credentials")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("export-graph takes no arguments, got %q", fs.Args())
+	}
+	if err := printGraph(io.Discard, Graph{}, o.graph.format); err != nil {
+		return err
+	}
+
+	if *live {
+		if err := runTenants(o, exportGraphMode, false); err != nil {
+			return err
+		}
+		return printGraph(os.Stdout, o.graph.graph(), o.graph.format)
+	}
+
+	if err := config.Load(o.configFile, false); err != nil {
+		return err
+	}
+	for _, t := range config.Tenants {
+		if err := loadTenant(t); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		for _, g := range groupsConfig.Groups {
+			o.graph.addGroup(g, groupsFilePath(g, config.GroupsPath))
+		}
+	}
+	return printGraph(os.Stdout, o.graph.graph(), o.graph.format)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// graphGroups are groups defined in two groups.yaml files, with a nested
+// group.
+var graphGroups = []struct {
+	g    GoogleGroup
+	path string
+}{
+	{
+		g: GoogleGroup{
+			EmailId: "k8s-infra-rbac-foo@example.com",
+			Owners:  []string{"Jane@example.com"},
+			Members: []string{"sig-foo-leads@example.com"},
+		},
+		path: "sig-k8s-infra/groups.yaml",
+	},
+	{
+		g: GoogleGroup{
+			EmailId: "sig-foo-leads@example.com",
+			Members: []string{"jane@example.com", "john@example.com"},
+		},
+		path: "sig-foo/groups.yaml",
+	},
+}
+
+func TestGraph(t *testing.T) {
+	cases := []struct {
+		desc          string
+		o             graphOptions
+		expectedNodes []GraphNode
+		expectedEdges []GraphEdge
+	}{
+		{
+			desc: "all groups",
+			expectedNodes: []GraphNode{
+				{ID: "k8s-infra-rbac-foo@example.com", Kind: GroupNodeKind, Path: "sig-k8s-infra/groups.yaml"},
+				{ID: "sig-foo-leads@example.com", Kind: GroupNodeKind, Path: "sig-foo/groups.yaml"},
+				{ID: "jane@example.com", Kind: PersonNodeKind},
+				{ID: "john@example.com", Kind: PersonNodeKind},
+			},
+			expectedEdges: []GraphEdge{
+				{From: "jane@example.com", To: "k8s-infra-rbac-foo@example.com", Role: OwnerRole},
+				{From: "sig-foo-leads@example.com", To: "k8s-infra-rbac-foo@example.com", Role: MemberRole},
+				{From: "jane@example.com", To: "sig-foo-leads@example.com", Role: MemberRole},
+				{From: "john@example.com", To: "sig-foo-leads@example.com", Role: MemberRole},
+			},
+		},
+		{
+			desc: "by prefix, groups only",
+			o:    graphOptions{prefixes: stringList{"K8s-Infra-RBAC-"}, groupsOnly: true},
+			expectedNodes: []GraphNode{
+				{ID: "k8s-infra-rbac-foo@example.com", Kind: GroupNodeKind, Path: "sig-k8s-infra/groups.yaml"},
+				{ID: "sig-foo-leads@example.com", Kind: GroupNodeKind},
+			},
+			expectedEdges: []GraphEdge{
+				{From: "sig-foo-leads@example.com", To: "k8s-infra-rbac-foo@example.com", Role: MemberRole},
+			},
+		},
+		{
+			desc: "by path",
+			o:    graphOptions{paths: stringList{"sig-foo/*"}},
+			expectedNodes: []GraphNode{
+				{ID: "sig-foo-leads@example.com", Kind: GroupNodeKind, Path: "sig-foo/groups.yaml"},
+				{ID: "jane@example.com", Kind: PersonNodeKind},
+				{ID: "john@example.com", Kind: PersonNodeKind},
+			},
+			expectedEdges: []GraphEdge{
+				{From: "jane@example.com", To: "sig-foo-leads@example.com", Role: MemberRole},
+				{From: "john@example.com", To: "sig-foo-leads@example.com", Role: MemberRole},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			for _, gg := range graphGroups {
+				c.o.addGroup(gg.g, gg.path)
+			}
+			actual := c.o.graph()
+			if !reflect.DeepEqual(actual.Nodes, c.expectedNodes) {
+				t.Errorf("expected nodes %v, got %v", c.expectedNodes, actual.Nodes)
+			}
+			if !reflect.DeepEqual(actual.Edges, c.expectedEdges) {
+				t.Errorf("expected edges %v, got %v", c.expectedEdges, actual.Edges)
+			}
+		})
+	}
+}
+
+func TestPrintGraph(t *testing.T) {
+	g := Graph{
+		Nodes: []GraphNode{
+			{ID: "sig-foo-leads@example.com", Kind: GroupNodeKind, Path: "sig-foo/groups.yaml"},
+			{ID: "jane@example.com", Kind: PersonNodeKind},
+		},
+		Edges: []GraphEdge{
+			{From: "jane@example.com", To: "sig-foo-leads@example.com", Role: OwnerRole},
+		},
+	}
+	cases := []struct {
+		format      string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			format: DOTGraphFormat,
+			expected: `digraph groups {
+  rankdir=LR;
+  "sig-foo-leads@example.com" [shape=box];
+  "jane@example.com" [shape=ellipse];
+  "jane@example.com" -> "sig-foo-leads@example.com" [label="OWNER"];
+}
+`,
+		},
+		{
+			format: MermaidGraphFormat,
+			expected: `flowchart LR
+  n0["sig-foo-leads@example.com"]
+  n1(["jane@example.com"])
+  n1 -- OWNER --> n0
+`,
+		},
+		{
+			format: JSONGraphFormat,
+			expected: `{
+  "nodes": [
+    {
+      "id": "sig-foo-leads@example.com",
+      "kind": "group",
+      "path": "sig-foo/groups.yaml"
+    },
+    {
+      "id": "jane@example.com",
+      "kind": "person"
+    }
+  ],
+  "edges": [
+    {
+      "from": "jane@example.com",
+      "to": "sig-foo-leads@example.com",
+      "role": "OWNER"
+    }
+  ]
+}
+`,
+		},
+		{
+			format:      "svg",
+			expectedErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			var out strings.Builder
+			err := printGraph(&out, g, c.format)
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			if out.String() != c.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestAddLiveGroups(t *testing.T) {
+	r, fakeAdminClient, _ := newLifecycleReconciler("", memorySnapshotStore{})
+	// group1 and a group of another domain are nested in group2.
+	for _, email := range []string{"group1@email.com", "external@other.com"} {
+		if _, err := fakeAdminClient.InsertMember("group2@email.com", &admin.Member{Email: email, Id: email, Role: MemberRole, Type: "GROUP"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := graphOptions{prefixes: stringList{"group2@"}, groupsOnly: true}
+	if err := r.addLiveGroups(&o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := o.graph()
+	expectedNodes := []GraphNode{
+		{ID: "external@other.com", Kind: GroupNodeKind},
+		{ID: "group1@email.com", Kind: GroupNodeKind},
+		{ID: "group2@email.com", Kind: GroupNodeKind},
+	}
+	if !reflect.DeepEqual(actual.Nodes, expectedNodes) {
+		t.Errorf("expected nodes %v, got %v", expectedNodes, actual.Nodes)
+	}
+	expectedEdges := []GraphEdge{
+		{From: "external@other.com", To: "group2@email.com", Role: MemberRole},
+		{From: "group1@email.com", To: "group2@email.com", Role: MemberRole},
+	}
+	if !reflect.DeepEqual(actual.Edges, expectedEdges) {
+		t.Errorf("expected edges %v, got %v", expectedEdges, actual.Edges)
+	}
+}
@@ -129,7 +129,7 @@ func TestStagingEmailLength(t *testing.T) {
 
 			len := utf8.RuneCountInString(projectName)
 			if len > 18 {
-				errs = append(errs, fmt.Errorf("Number of characters in project name \"%s\" should not exceed 18; is: %d", projectName, len))
+				errs = append(errs, fmt.Errorf("%s: Number of characters in project name \"%s\" should not exceed 18; is: %d", g.Position(), projectName, len))
 			}
 		}
 	}
@@ -155,8 +155,8 @@ func TestDescriptionLength(t *testing.T) {
 		// Ref: https://developers.google.com/admin-sdk/groups-settings/v1/reference/groups
 		if len > 300 {
 			errs = append(errs,
-				fmt.Errorf("Number of characters in description \"%s\" for group name \"%s\" "+
-					"should not exceed 300; is: %d", description, g.Name, len))
+				fmt.Errorf("%s: Number of characters in description \"%s\" for group name \"%s\" "+
+					"should not exceed 300; is: %d", g.Position(), description, g.Name, len))
 		}
 	}
 
@@ -180,7 +180,7 @@ func TestGroupConventions(t *testing.T) {
 			expectedEmailId = "security@etcd.io"
 		}
 		if g.EmailId != expectedEmailId {
-			t.Errorf("group '%s': expected email '%s', got '%s'", g.Name, expectedEmailId, g.EmailId)
+			t.Errorf("%s: group '%s': expected email '%s', got '%s'", g.Position(), g.Name, expectedEmailId, g.EmailId)
 		}
 	}
 }
@@ -191,13 +191,13 @@ func TestK8sInfraGroupConventions(t *testing.T) {
 		if strings.HasPrefix(g.EmailId, "k8s-infra") {
 			// no owners because we want to prevent manual membership changes
 			if len(g.Owners) > 0 {
-				t.Errorf("group '%s': must have no owners, only members", g.Name)
+				t.Errorf("%s: group '%s': must have no owners, only members", g.MemberPosition(OwnerRole, 0), g.Name)
 			}
 
 			// treat files here as source of truth for membership
 			reconcileMembers, ok := g.Settings["ReconcileMembers"]
 			if !ok || reconcileMembers != "true" {
-				t.Errorf("group '%s': must have settings.ReconcileMembers = true", g.Name)
+				t.Errorf("%s: group '%s': must have settings.ReconcileMembers = true", g.Position(), g.Name)
 			}
 		}
 	}
@@ -205,17 +205,20 @@ func TestK8sInfraGroupConventions(t *testing.T) {
 
 // Enforce conventions for groups used by GKE Group-based RBAC
 // - there must be a gke-security-groups@ group
-// - its members must be k8s-infra-rbac-*@ groups (and vice-versa)
+// - its members must be k8s-infra-rbac-*@ groups (and vice-versa, unless they have the security label)
 // - all groups involved must have settings.WhoCanViewMembership = ALL_MEMBERS_CAN_VIEW
 func TestK8sInfraRBACGroupConventions(t *testing.T) {
 	rbacEmails := make(map[string]bool)
+	rbacPositions := make(map[string]Position)
 	for _, g := range cfg.Groups {
 		if strings.HasPrefix(g.EmailId, "k8s-infra-rbac") {
-			rbacEmails[g.EmailId] = false
+			_, security := g.Labels[SecurityLabel]
+			rbacEmails[g.EmailId] = security
+			rbacPositions[g.EmailId] = g.Position()
 			// this is necessary for group-based rbac to work
 			whoCanViewMembership, ok := g.Settings["WhoCanViewMembership"]
 			if !ok || whoCanViewMembership != "ALL_MEMBERS_CAN_VIEW" {
-				t.Errorf("group '%s': must have settings.WhoCanViewMembership = ALL_MEMBERS_CAN_VIEW", g.Name)
+				t.Errorf("%s: group '%s': must have settings.WhoCanViewMembership = ALL_MEMBERS_CAN_VIEW", g.Position(), g.Name)
 			}
 		}
 	}
@@ -226,11 +229,11 @@ func TestK8sInfraRBACGroupConventions(t *testing.T) {
 			// this is necessary for group-based rbac to work
 			whoCanViewMembership, ok := g.Settings["WhoCanViewMembership"]
 			if !ok || whoCanViewMembership != "ALL_MEMBERS_CAN_VIEW" {
-				t.Errorf("group '%s': must have settings.WhoCanViewMembership = ALL_MEMBERS_CAN_VIEW", g.Name)
+				t.Errorf("%s: group '%s': must have settings.WhoCanViewMembership = ALL_MEMBERS_CAN_VIEW", g.Position(), g.Name)
 			}
-			for _, email := range g.Members {
+			for i, email := range g.Members {
 				if _, ok := rbacEmails[email]; !ok {
-					t.Errorf("group '%s': invalid member '%s', must be a k8s-infra-rbac-*@kubernetes.io group", g.Name, email)
+					t.Errorf("%s: group '%s': invalid member '%s', must be a k8s-infra-rbac-*@kubernetes.io group", g.MemberPosition(MemberRole, i), g.Name, email)
 				} else {
 					rbacEmails[email] = true
 				}
@@ -242,7 +245,7 @@ func TestK8sInfraRBACGroupConventions(t *testing.T) {
 	}
 	for email, found := range rbacEmails {
 		if !found {
-			t.Errorf("group '%s': must be a member of gke-security-groups@kubernetes.io", email)
+			t.Errorf("%s: group '%s': must be a member of gke-security-groups@kubernetes.io", rbacPositions[email], email)
 		}
 	}
 }
@@ -266,7 +269,7 @@ func TestSecurityResponseCommitteeGroups(t *testing.T) {
 		for _, g := range cfg.Groups {
 			if g.EmailId == pscGroup {
 				if !reflect.DeepEqual(owners, g.Owners) {
-					t.Errorf("group '%s': owners must match owners from security@kubernetes.io, expected: %v, actual: %v", pscGroup, owners, g.Owners)
+					t.Errorf("%s: group '%s': owners must match owners from security@kubernetes.io, expected: %v, actual: %v", g.Position(), pscGroup, owners, g.Owners)
 				}
 				break
 			}
@@ -275,38 +278,12 @@ func TestSecurityResponseCommitteeGroups(t *testing.T) {
 }
 
 // An e-mail address can only show up once within a given group, whether that
-// be as a member, manager, or owner
+// be as a member, manager, or owner. Addresses are compared by CanonicalEmail,
+// so the same person listed under two spellings is a duplicate too.
 func TestNoDuplicateMembers(t *testing.T) {
 	for _, g := range cfg.Groups {
-		members := map[string]bool{}
-		for _, m := range g.Members {
-			if _, ok := members[m]; ok {
-				t.Errorf("group '%s' cannot have duplicate member '%s'", g.EmailId, m)
-			}
-			members[m] = true
-		}
-		managers := map[string]bool{}
-		for _, m := range g.Managers {
-			if _, ok := members[m]; ok {
-				t.Errorf("group '%s' manager '%s' cannot also be listed as a member", g.EmailId, m)
-			}
-			if _, ok := managers[m]; ok {
-				t.Errorf("group '%s' cannot have duplicate manager '%s'", g.EmailId, m)
-			}
-			managers[m] = true
-		}
-		owners := map[string]bool{}
-		for _, m := range g.Owners {
-			if _, ok := members[m]; ok {
-				t.Errorf("group '%s' owner '%s' cannot also be listed as a member", g.EmailId, m)
-			}
-			if _, ok := managers[m]; ok {
-				t.Errorf("group '%s' owner '%s' cannot also be listed as a manager", g.EmailId, m)
-			}
-			if _, ok := owners[m]; ok {
-				t.Errorf("group '%s' cannot have duplicate owner '%s'", g.EmailId, m)
-			}
-			owners[m] = true
+		for _, err := range checkDuplicateMembers(g) {
+			t.Error(err)
 		}
 	}
 }
@@ -336,7 +313,7 @@ func TestHardcodedGroupsForParanoia(t *testing.T) {
 			copy(actual, g.Members)
 			sort.Strings(actual)
 			if !reflect.DeepEqual(expected, actual) {
-				t.Errorf("group '%s': expected members '%v', got '%v'", g.Name, expected, actual)
+				t.Errorf("%s: group '%s': expected members '%v', got '%v'", g.Position(), g.Name, expected, actual)
 			}
 		}
 	}
@@ -368,13 +345,15 @@ func TestGroupsWhichShouldSupportHistory(t *testing.T) {
 			allowedWebPosting, ok := group.Settings["AllowWebPosting"]
 			if !ok {
 				t.Errorf(
-					"group '%s': must have 'settings.allowedWebPosting = true'",
+					"%s: group '%s': must have 'settings.allowedWebPosting = true'",
+					group.Position(),
 					group.Name,
 				)
 			} else if allowedWebPosting != "true" {
 				t.Errorf(
-					"group '%s': must have 'settings.allowedWebPosting = true'"+
+					"%s: group '%s': must have 'settings.allowedWebPosting = true'"+
 						" but have 'settings.allowedWebPosting = %s' instead",
+					group.Position(),
 					group.Name,
 					allowedWebPosting,
 				)
@@ -388,3 +367,21 @@ func TestGroupsWhichShouldSupportHistory(t *testing.T) {
 		}
 	}
 }
+
+// TestSensitivePathsMatch tests that each sensitive path of the restrictions
+// matches a groups.yaml file, so that a typo or a moved file doesn't lift the
+// approval of the changes to its groups.
+func TestSensitivePathsMatch(t *testing.T) {
+	for _, path := range rConfig.Sensitive.Paths {
+		matched := false
+		for _, g := range cfg.Groups {
+			if matchesGlob(groupsFilePath(g, *groupsPath), []string{path}) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			t.Errorf("sensitive path %q matches no groups.yaml file", path)
+		}
+	}
+}
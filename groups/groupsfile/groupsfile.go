@@ -0,0 +1,440 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package groupsfile edits the owners, managers and members of the groups
+// defined in a groups.yaml file. Edits are made to the lines of the file,
+// located with its yaml.v3 node tree, so that comments, blank lines and the
+// order of everything else are preserved.
+//
+// The package only edits files. The member command of the groups binary
+// also finds the file defining a group and refuses edits the restrictions
+// or the conventions of the repository don't allow.
+package groupsfile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The fields of a group listing addresses.
+const (
+	OwnersField   = "owners"
+	ManagersField = "managers"
+	MembersField  = "members"
+	DeliveryField = "delivery"
+	ScheduleField = "schedule"
+)
+
+// RoleFields are the fields listing the addresses of each role, in the
+// order they are written in a group.
+var RoleFields = []string{OwnersField, ManagersField, MembersField}
+
+// File is a groups.yaml file being edited.
+type File struct {
+	// Path is where the file is read from and written to.
+	Path string
+	// Equal reports whether two addresses are the same. If nil, addresses
+	// are compared case-insensitively.
+	Equal func(a, b string) bool
+
+	lines []string
+	doc   yaml.Node
+}
+
+// Read reads the groups.yaml file at path for editing.
+func Read(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading groups config file %s: %w", path, err)
+	}
+	return Parse(path, content)
+}
+
+// Parse parses content, the groups.yaml file at path, for editing.
+func Parse(path string, content []byte) (*File, error) {
+	f := &File{Path: path, lines: strings.Split(string(content), "\n")}
+	if err := f.parse(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Bytes returns the content of f.
+func (f *File) Bytes() []byte {
+	return []byte(strings.Join(f.lines, "\n"))
+}
+
+// Write writes f back to its path.
+func (f *File) Write() error {
+	return os.WriteFile(f.Path, f.Bytes(), 0644)
+}
+
+// HasGroup reports whether f defines the group with the given email.
+func (f *File) HasGroup(group string) bool {
+	return f.group(group) != nil
+}
+
+// Fields returns the fields among RoleFields of the group listing email.
+func (f *File) Fields(group, email string) ([]string, error) {
+	g := f.group(group)
+	if g == nil {
+		return nil, f.errUndefined(group)
+	}
+	var fields []string
+	for _, field := range RoleFields {
+		if list := mappingValue(g, field); list != nil && list.Kind == yaml.SequenceNode {
+			for _, item := range list.Content {
+				if f.equal(item.Value, email) {
+					fields = append(fields, field)
+					break
+				}
+			}
+		}
+	}
+	return fields, nil
+}
+
+// Add adds email to field, one of RoleFields, of the group. The address is
+// inserted in order among the first addresses of the list, up to the first
+// comment on a line of its own between addresses, as comments like
+// "# emeritus" describe the addresses following them. The field is added if
+// the group has none.
+//
+// It returns an error if the group already lists email in any field.
+func (f *File) Add(group, field, email string) error {
+	if !isRoleField(field) {
+		return fmt.Errorf("unknown field %q, must be one of %s", field, strings.Join(RoleFields, ", "))
+	}
+	fields, err := f.Fields(group, email)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return fmt.Errorf("group %s already lists %s in %s", group, email, strings.Join(fields, ", "))
+	}
+
+	g := f.group(group)
+	key, list := mappingEntry(g, field)
+	if key == nil {
+		return f.addField(g, field, email)
+	}
+	if list.Kind == yaml.SequenceNode && list.Style&yaml.FlowStyle != 0 || list.Kind != yaml.SequenceNode && list.Tag != "!!null" {
+		return f.errorAt(list, "can't add to %s of group %s, which isn't a list on lines of its own", field, group)
+	}
+
+	indent := strings.Repeat(" ", key.Column+1)
+	line := key.Line + 1
+	if list.Kind == yaml.SequenceNode && len(list.Content) > 0 {
+		first := list.Content[0]
+		indent = strings.Repeat(" ", first.Column-3)
+		line = first.Line
+		for _, item := range list.Content {
+			if f.hasCommentLine(line, item.Line) || strings.ToLower(item.Value) > strings.ToLower(email) {
+				break
+			}
+			line = item.Line + 1
+		}
+	}
+	return f.insertLines(line, indent+"- "+email)
+}
+
+// Remove removes the addresses equal to email from the fields among
+// RoleFields of the group, and their delivery settings and schedules. Fields
+// left empty are removed, with the comments in them. It returns the fields
+// email was removed from.
+func (f *File) Remove(group, email string) ([]string, error) {
+	return f.remove(group, email, append(append([]string{}, RoleFields...), DeliveryField, ScheduleField))
+}
+
+// SetField moves email, which the group must list, to field, one of
+// RoleFields, keeping its delivery setting and schedule. It returns the
+// fields email was removed from.
+func (f *File) SetField(group, email, field string) ([]string, error) {
+	if !isRoleField(field) {
+		return nil, fmt.Errorf("unknown field %q, must be one of %s", field, strings.Join(RoleFields, ", "))
+	}
+	fields, err := f.Fields(group, email)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("group %s doesn't list %s", group, email)
+	}
+	if len(fields) == 1 && fields[0] == field {
+		return nil, nil
+	}
+	removed, err := f.remove(group, email, RoleFields)
+	if err != nil {
+		return nil, err
+	}
+	return removed, f.Add(group, field, email)
+}
+
+// remove removes the addresses equal to email from the given fields of the
+// group, see Remove.
+func (f *File) remove(group, email string, fields []string) ([]string, error) {
+	g := f.group(group)
+	if g == nil {
+		return nil, f.errUndefined(group)
+	}
+
+	var (
+		removedFrom []string
+		lines       []int
+	)
+	for _, field := range fields {
+		key, value := mappingEntry(g, field)
+		if key == nil {
+			continue
+		}
+		// items are the addresses of the field, and ends the last line of
+		// each, as the schedule of an address spans several lines.
+		var items []*yaml.Node
+		ends := map[*yaml.Node]int{}
+		isRole := isRoleField(field)
+		switch {
+		case !isRole && value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				items = append(items, value.Content[j])
+				ends[value.Content[j]] = nodeEnd(value.Content[j+1])
+			}
+		case isRole && value.Kind == yaml.SequenceNode:
+			items = value.Content
+		}
+
+		removed := 0
+		for _, item := range items {
+			if !f.equal(item.Value, email) {
+				continue
+			}
+			if value.Style&yaml.FlowStyle != 0 || item.Line == key.Line {
+				return nil, f.errorAt(item, "can't remove %s from %s of group %s, which isn't on lines of its own", item.Value, field, group)
+			}
+			for n := item.Line; n <= max(item.Line, ends[item]); n++ {
+				lines = append(lines, n)
+			}
+			removed++
+			if isRole {
+				removedFrom = append(removedFrom, field)
+			}
+		}
+		if removed > 0 && removed == len(items) {
+			// Also remove the comments of the field.
+			for n := key.Line; n <= nodeEnd(value); n++ {
+				lines = append(lines, n)
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	return removedFrom, f.deleteLines(lines)
+}
+
+// addField adds field, listing email, to the group g. It is added after the
+// last of the role fields preceding it in RoleFields, else before the first
+// of those following it, else at the end of the group.
+func (f *File) addField(g *yaml.Node, field, email string) error {
+	indent := strings.Repeat(" ", g.Content[0].Column-1)
+	lines := []string{indent + field + ":", indent + "  - " + email}
+
+	var before, after *yaml.Node
+	preceding := true
+	for _, other := range RoleFields {
+		if other == field {
+			preceding = false
+			continue
+		}
+		key, value := mappingEntry(g, other)
+		switch {
+		case key == nil:
+		case preceding:
+			before = value
+		case after == nil:
+			after = key
+		}
+	}
+	switch {
+	case before != nil:
+		return f.insertLines(nodeEnd(before)+1, lines...)
+	case after != nil:
+		// Comments right above a field describe it.
+		line := after.Line
+		for line > 1 && isCommentLine(f.lines[line-2]) {
+			line--
+		}
+		return f.insertLines(line, lines...)
+	default:
+		return f.insertLines(f.groupEnd(g)+1, lines...)
+	}
+}
+
+// parse parses the lines of f into its node tree.
+func (f *File) parse() error {
+	f.doc = yaml.Node{}
+	if err := yaml.Unmarshal(f.Bytes(), &f.doc); err != nil {
+		return fmt.Errorf("error parsing groups config at %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// groups returns the sequence node of the groups of f, or nil.
+func (f *File) groups() *yaml.Node {
+	if len(f.doc.Content) == 0 {
+		return nil
+	}
+	groups := mappingValue(f.doc.Content[0], "groups")
+	if groups == nil || groups.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return groups
+}
+
+// group returns the mapping node of the group with the given email, or nil
+// if f doesn't define it.
+func (f *File) group(email string) *yaml.Node {
+	groups := f.groups()
+	if groups == nil {
+		return nil
+	}
+	for _, g := range groups.Content {
+		if id := mappingValue(g, "email-id"); id != nil && f.equal(id.Value, email) {
+			return g
+		}
+	}
+	return nil
+}
+
+// groupEnd returns the last line of the group g, leaving out the blank and
+// comment lines following it, which usually belong to the next group.
+func (f *File) groupEnd(g *yaml.Node) int {
+	end := len(f.lines)
+	if groups := f.groups(); groups != nil {
+		for _, other := range groups.Content {
+			if other.Line > g.Line {
+				end = other.Line - 1
+				break
+			}
+		}
+	}
+	for _, n := range f.doc.Content[0].Content {
+		if n.Line > g.Line && n.Line-1 < end {
+			end = n.Line - 1
+		}
+	}
+	for end > g.Line && (strings.TrimSpace(f.lines[end-1]) == "" || isCommentLine(f.lines[end-1])) {
+		end--
+	}
+	if last := nodeEnd(g); last > end {
+		end = last
+	}
+	return end
+}
+
+// hasCommentLine reports whether any of the lines from from to to, excluded
+// (1-based), is a comment on a line of its own.
+func (f *File) hasCommentLine(from, to int) bool {
+	for n := from; n < to; n++ {
+		if isCommentLine(f.lines[n-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// insertLines inserts lines before the given line (1-based) of f and parses
+// it again.
+func (f *File) insertLines(line int, lines ...string) error {
+	f.lines = append(f.lines[:line-1], append(lines, f.lines[line-1:]...)...)
+	return f.parse()
+}
+
+// deleteLines deletes the given lines (1-based) of f and parses it again.
+func (f *File) deleteLines(lines []int) error {
+	sort.Sort(sort.Reverse(sort.IntSlice(lines)))
+	for i, n := range lines {
+		if i > 0 && n == lines[i-1] {
+			continue
+		}
+		f.lines = append(f.lines[:n-1], f.lines[n:]...)
+	}
+	return f.parse()
+}
+
+func (f *File) equal(a, b string) bool {
+	if f.Equal != nil {
+		return f.Equal(a, b)
+	}
+	return strings.EqualFold(a, b)
+}
+
+func (f *File) errUndefined(group string) error {
+	return fmt.Errorf("%s doesn't define group %s", f.Path, group)
+}
+
+// errorAt returns an error formatted like fmt.Errorf, prefixed with the
+// position of node in f.
+func (f *File) errorAt(node *yaml.Node, format string, a ...interface{}) error {
+	return fmt.Errorf("%s:%d:%d: %w", f.Path, node.Line, node.Column, fmt.Errorf(format, a...))
+}
+
+// mappingEntry returns the key and value nodes of key in the mapping node,
+// or nils if node isn't a mapping or doesn't have key.
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// mappingValue returns the value of key in the mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
+
+// nodeEnd returns the last line of node and its descendants.
+func nodeEnd(node *yaml.Node) int {
+	end := node.Line
+	for _, n := range node.Content {
+		if e := nodeEnd(n); e > end {
+			end = e
+		}
+	}
+	return end
+}
+
+func isCommentLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+func isRoleField(field string) bool {
+	for _, f := range RoleFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
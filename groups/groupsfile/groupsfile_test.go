@@ -0,0 +1,287 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groupsfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const content = `groups:
+  # The first group.
+  - email-id: group1@example.com
+    name: group1
+    owners:
+      - owner@example.com # lead
+      - Jane@example.com
+    members:
+      - bob@example.com
+      - mallory@example.com
+      # emeritus
+      - jane+k8s@example.com
+      - other@example.com
+    delivery:
+      jane@example.com: DIGEST
+    schedule:
+      bob@example.com:
+        from: 2026-06-01T00:00:00Z
+      other@example.com:
+        # Steps down.
+        until: 2026-09-01T00:00:00Z
+
+  # The second group.
+  - email-id: group2@example.com
+    name: group2
+    settings:
+      WhoCanViewGroup: "ALL_MEMBERS_CAN_VIEW"
+    members:
+      - jane@example.com
+
+  - email-id: group3@example.com
+    name: group3
+    # No members yet.
+`
+
+func TestRemove(t *testing.T) {
+	cases := []struct {
+		desc           string
+		group          string
+		email          string
+		expected       string
+		expectedFields []string
+		expectedErr    bool
+	}{
+		{
+			desc:  "from several fields and delivery, keeping comments",
+			group: "group1@example.com",
+			email: "jane@example.com",
+			expected: strings.Replace(strings.Replace(content,
+				"      - Jane@example.com\n", "", 1),
+				"    delivery:\n      jane@example.com: DIGEST\n", "", 1),
+			expectedFields: []string{OwnersField},
+		},
+		{
+			desc:  "with its schedule",
+			group: "group1@example.com",
+			email: "other@example.com",
+			expected: strings.Replace(strings.Replace(content,
+				"      - other@example.com\n", "", 1),
+				"      other@example.com:\n        # Steps down.\n        until: 2026-09-01T00:00:00Z\n", "", 1),
+			expectedFields: []string{MembersField},
+		},
+		{
+			desc:           "last address of a field",
+			group:          "group2@example.com",
+			email:          "jane@example.com",
+			expected:       strings.Replace(content, "    members:\n      - jane@example.com\n", "", 1),
+			expectedFields: []string{MembersField},
+		},
+		{
+			desc:  "address that isn't listed",
+			group: "group2@example.com",
+			email: "other@example.com",
+		},
+		{
+			desc:        "undefined group",
+			group:       "group4@example.com",
+			email:       "other@example.com",
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			f, err := Parse("groups.yaml", []byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields, err := f.Remove(c.group, c.email)
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			if !reflect.DeepEqual(c.expectedFields, fields) {
+				t.Errorf("expected fields %v, got %v", c.expectedFields, fields)
+			}
+			expected := c.expected
+			if expected == "" {
+				expected = content
+			}
+			if actual := string(f.Bytes()); actual != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	cases := []struct {
+		desc        string
+		group       string
+		field       string
+		email       string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			desc:  "in order, before the first comment",
+			group: "group1@example.com",
+			field: MembersField,
+			email: "carol@example.com",
+			expected: strings.Replace(content,
+				"      - bob@example.com\n", "      - bob@example.com\n      - carol@example.com\n", 1),
+		},
+		{
+			desc:  "after the addresses before the first comment",
+			group: "group1@example.com",
+			field: MembersField,
+			email: "zoe@example.com",
+			expected: strings.Replace(content,
+				"      - mallory@example.com\n", "      - mallory@example.com\n      - zoe@example.com\n", 1),
+		},
+		{
+			desc:  "first address",
+			group: "group1@example.com",
+			field: OwnersField,
+			email: "alice@example.com",
+			expected: strings.Replace(content,
+				"    owners:\n", "    owners:\n      - alice@example.com\n", 1),
+		},
+		{
+			desc:  "missing field, after the preceding field",
+			group: "group1@example.com",
+			field: ManagersField,
+			email: "alice@example.com",
+			expected: strings.Replace(content,
+				"      - Jane@example.com\n", "      - Jane@example.com\n    managers:\n      - alice@example.com\n", 1),
+		},
+		{
+			desc:  "missing field, before the following field",
+			group: "group2@example.com",
+			field: OwnersField,
+			email: "alice@example.com",
+			expected: strings.Replace(content,
+				"      WhoCanViewGroup: \"ALL_MEMBERS_CAN_VIEW\"\n", "      WhoCanViewGroup: \"ALL_MEMBERS_CAN_VIEW\"\n    owners:\n      - alice@example.com\n", 1),
+		},
+		{
+			desc:  "missing field, at the end of the group",
+			group: "group3@example.com",
+			field: MembersField,
+			email: "alice@example.com",
+			expected: strings.Replace(content,
+				"    name: group3\n", "    name: group3\n    members:\n      - alice@example.com\n", 1),
+		},
+		{
+			desc:        "address already listed",
+			group:       "group1@example.com",
+			field:       MembersField,
+			email:       "JANE@example.com",
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown field",
+			group:       "group1@example.com",
+			field:       DeliveryField,
+			email:       "alice@example.com",
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			f, err := Parse("groups.yaml", []byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = f.Add(c.group, c.field, c.email)
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			expected := c.expected
+			if expected == "" {
+				expected = content
+			}
+			if actual := string(f.Bytes()); actual != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestSetField(t *testing.T) {
+	f, err := Parse("groups.yaml", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := f.SetField("group1@example.com", "jane@example.com", MembersField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{OwnersField}; !reflect.DeepEqual(expected, fields) {
+		t.Errorf("expected fields %v, got %v", expected, fields)
+	}
+	expected := strings.Replace(strings.Replace(content,
+		"      - Jane@example.com\n", "", 1),
+		"      - bob@example.com\n", "      - bob@example.com\n      - jane@example.com\n", 1)
+	if actual := string(f.Bytes()); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	if _, err := f.SetField("group1@example.com", "alice@example.com", MembersField); err == nil {
+		t.Errorf("expected an error setting the field of an address that isn't listed")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ignore the suffixes of addresses, like the groups binary does for
+	// gmail.com addresses.
+	canonical := func(email string) string {
+		local, domain, _ := strings.Cut(strings.ToLower(email), "@")
+		local, _, _ = strings.Cut(local, "+")
+		return local + "@" + domain
+	}
+	f.Equal = func(a, b string) bool {
+		return canonical(a) == canonical(b)
+	}
+	if _, err := f.Remove("group1@example.com", "jane@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(strings.Replace(strings.Replace(content,
+		"      - Jane@example.com\n", "", 1),
+		"      - jane+k8s@example.com\n", "", 1),
+		"    delivery:\n      jane@example.com: DIGEST\n", "", 1)
+	if string(written) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, written)
+	}
+}
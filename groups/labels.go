@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Labels of Cloud Identity groups, see
+// https://cloud.google.com/identity/docs/reference/rest/v1/groups
+const (
+	// DiscussionForumLabel is set on every group with an email address.
+	DiscussionForumLabel = "cloudidentity.googleapis.com/groups.discussion_forum"
+	// SecurityLabel makes a group a security group. It can't be removed
+	// once set.
+	SecurityLabel = "cloudidentity.googleapis.com/groups.security"
+)
+
+// LabelService provides functionality to perform high level
+// tasks using a CloudIdentityClient.
+type LabelService interface {
+	UpdateGroupLabels(group GoogleGroup) error
+	// GetLabels returns the labels of the group with the given email.
+	GetLabels(email string) (map[string]string, error)
+}
+
+func NewLabelService(ctx context.Context, clientOptions ...option.ClientOption) (LabelService, error) {
+	client, err := NewCloudIdentityClient(ctx, clientOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLabelServiceWithClient(client)
+}
+
+func NewLabelServiceWithClient(client CloudIdentityClient) (LabelService, error) {
+	errFunc := func(err error) bool {
+		apierr, ok := err.(*googleapi.Error)
+		return ok && apierr.Code == http.StatusNotFound
+	}
+	return &labelService{
+		client:            client,
+		checkForAPIErr404: errFunc,
+	}, nil
+}
+
+type labelService struct {
+	client            CloudIdentityClient
+	checkForAPIErr404 clientErrCheckFunc
+}
+
+// UpdateGroupLabels adds the labels of the passed group that the existing
+// group doesn't have, and updates those whose value differs. Its other
+// labels are kept, as labels such as DiscussionForumLabel are set by Cloud
+// Identity itself.
+func (ls *labelService) UpdateGroupLabels(group GoogleGroup) error {
+	if len(group.Labels) == 0 {
+		return nil
+	}
+	if *verbose {
+		log.Printf("labelService.UpdateGroupLabels %s", group.EmailId)
+	}
+	name, err := ls.client.LookupGroupName(group.EmailId)
+	if err != nil {
+		if ls.checkForAPIErr404(err) {
+			log.Printf("skipping updating labels as group %q has not yet been created\n", group.EmailId)
+			return nil
+		}
+		return fmt.Errorf("unable to look up group %q: %w", group.EmailId, err)
+	}
+	g, err := ls.client.GetGroup(name)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve labels of group %q: %w", group.EmailId, err)
+	}
+
+	labels := map[string]string{}
+	for key, value := range g.Labels {
+		labels[key] = value
+	}
+	var changed []string
+	for key, value := range group.Labels {
+		if have, ok := labels[key]; !ok || have != value {
+			labels[key] = value
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Strings(changed)
+
+	if !config.ConfirmChanges {
+		log.Printf("dry-run: would set labels %s of group %q\n", strings.Join(changed, ", "), group.EmailId)
+		return nil
+	}
+	if err := ls.client.PatchGroupLabels(name, labels); err != nil {
+		return fmt.Errorf("unable to update labels of group %q: %w", group.EmailId, err)
+	}
+	log.Printf("> Successfully set labels %s of group %q\n", strings.Join(changed, ", "), group.EmailId)
+	return nil
+}
+
+func (ls *labelService) GetLabels(email string) (map[string]string, error) {
+	name, err := ls.client.LookupGroupName(email)
+	if err != nil {
+		return nil, err
+	}
+	g, err := ls.client.GetGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	return g.Labels, nil
+}
+
+var _ LabelService = (*labelService)(nil)
+
+// extraScopes returns the OAuth scopes requested for the tenant in addition
+// to credentialScopes.
+func (t *Tenant) extraScopes() []string {
+	if t.CloudIdentity {
+		return []string{cloudidentity.CloudIdentityGroupsScope}
+	}
+	return nil
+}
+
+// CheckGroupLabels returns an error for each group that sets labels if the
+// tenant doesn't enable cloud-identity, as they would silently not be
+// applied.
+func (t *Tenant) CheckGroupLabels(groups []GoogleGroup) error {
+	if t.CloudIdentity {
+		return nil
+	}
+	var errs []error
+	for _, g := range groups {
+		if len(g.Labels) > 0 {
+			errs = append(errs, errorAt(g.Position(), "group %s sets labels, which requires cloud-identity to be enabled for tenant %s", g.EmailId, t.Name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
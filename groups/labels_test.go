@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/k8s.io/groups/fake"
+)
+
+func TestUpdateGroupLabels(t *testing.T) {
+	defer func() { config.ConfirmChanges = false }()
+
+	cases := []struct {
+		desc           string
+		g              GoogleGroup
+		dryRun         bool
+		fault          *fake.Fault
+		expectedLabels map[string]string
+		expectedErr    bool
+	}{
+		{
+			desc:           "no labels",
+			g:              GoogleGroup{EmailId: "group1@email.com"},
+			expectedLabels: map[string]string{DiscussionForumLabel: ""},
+		},
+		{
+			desc:           "labels already set",
+			g:              GoogleGroup{EmailId: "group1@email.com", Labels: map[string]string{DiscussionForumLabel: ""}},
+			expectedLabels: map[string]string{DiscussionForumLabel: ""},
+		},
+		{
+			desc:           "security label added, other labels kept",
+			g:              GoogleGroup{EmailId: "group1@email.com", Labels: map[string]string{SecurityLabel: ""}},
+			expectedLabels: map[string]string{DiscussionForumLabel: "", SecurityLabel: ""},
+		},
+		{
+			desc:           "dry-run",
+			g:              GoogleGroup{EmailId: "group1@email.com", Labels: map[string]string{SecurityLabel: ""}},
+			dryRun:         true,
+			expectedLabels: map[string]string{DiscussionForumLabel: ""},
+		},
+		{
+			desc: "group not yet created",
+			g:    GoogleGroup{EmailId: "new@email.com", Labels: map[string]string{SecurityLabel: ""}},
+		},
+		{
+			desc:           "patch fails",
+			g:              GoogleGroup{EmailId: "group1@email.com", Labels: map[string]string{SecurityLabel: ""}},
+			fault:          &fake.Fault{Method: "PatchGroupLabels", Code: http.StatusForbidden},
+			expectedLabels: map[string]string{DiscussionForumLabel: ""},
+			expectedErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			config.ConfirmChanges = !c.dryRun
+			fakeClient := fake.NewAugmentedFakeCloudIdentityClient()
+			if c.fault != nil {
+				fakeClient.AddFault(*c.fault)
+			}
+			labelSvc, err := NewLabelServiceWithClient(fakeClient)
+			if err != nil {
+				t.Fatalf("error creating client %v", err)
+			}
+
+			err = labelSvc.UpdateGroupLabels(c.g)
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			if c.expectedLabels == nil {
+				return
+			}
+			actual, err := labelSvc.GetLabels(c.g.EmailId)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, c.expectedLabels) {
+				t.Errorf("expected labels %v, got %v", c.expectedLabels, actual)
+			}
+		})
+	}
+}
+
+func TestReconcileGroupLabels(t *testing.T) {
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+	}()
+
+	group1 := GoogleGroup{EmailId: "group1@email.com", Members: []string{"m1-group1@email.com"}, Managers: []string{"m2-group1@email.com"}, Labels: map[string]string{SecurityLabel: ""}}
+	group2 := GoogleGroup{EmailId: "group2@email.com", Members: []string{"m1-group2@email.com"}, Owners: []string{"m2-group2@email.com"}}
+	groupsConfig.Groups = []GoogleGroup{group1, group2}
+	r, _, _ := newLifecycleReconciler("", memorySnapshotStore{})
+	fakeClient := fake.NewAugmentedFakeCloudIdentityClient()
+	r.labelService, _ = NewLabelServiceWithClient(fakeClient)
+
+	var out strings.Builder
+	if err := r.DiffGroups(&out, groupsConfig.Groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "+    "+SecurityLabel+": \"\"") {
+		t.Errorf("expected the diff to add the security label, got:\n%s", out.String())
+	}
+
+	if err := r.ReconcileGroups(groupsConfig.Groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]map[string]string{
+		"groups/group1": {DiscussionForumLabel: "", SecurityLabel: ""},
+		"groups/group2": {DiscussionForumLabel: ""},
+	}
+	for name, labels := range expected {
+		if actual := fakeClient.Groups[name].Labels; !reflect.DeepEqual(actual, labels) {
+			t.Errorf("expected labels %v for %s, got %v", labels, name, actual)
+		}
+	}
+
+	out.Reset()
+	if err := r.DiffGroups(&out, groupsConfig.Groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "" {
+		t.Errorf("expected no diff once reconciled, got:\n%s", out.String())
+	}
+}
+
+func TestCheckGroupLabels(t *testing.T) {
+	groups := []GoogleGroup{
+		{EmailId: "group1@email.com", Labels: map[string]string{SecurityLabel: ""}},
+		{EmailId: "group2@email.com"},
+	}
+	tenant := Tenant{Name: "default"}
+	err := tenant.CheckGroupLabels(groups)
+	if err == nil || !strings.Contains(err.Error(), "group group1@email.com sets labels, which requires cloud-identity") {
+		t.Errorf("expected an error about the labels of group1, got %v", err)
+	}
+
+	tenant.CloudIdentity = true
+	if err := tenant.CheckGroupLabels(groups); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
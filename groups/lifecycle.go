@@ -0,0 +1,322 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Groups removed from the groups.yaml files are not deleted right away.
+// They are first locked: nobody can post to them or join them, managers
+// can't change their members, and their description records when they may
+// be deleted. They are deleted, after saving a snapshot of them, once that
+// time has passed or when purged. The description is the only state kept,
+// so that a locked group that is added back is unlocked by reconciling it.
+
+// defaultDeletionGracePeriod is how long a group removed from the
+// groups.yaml files is kept locked if the tenant doesn't set it.
+const defaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// pendingDeletionFormat is appended to the description of a locked group,
+// with the time after which it may be deleted.
+const pendingDeletionFormat = "Pending deletion after %s, as it was removed from the groups.yaml files."
+
+var pendingDeletionRe = regexp.MustCompile(`\n*Pending deletion after (\S+), as it was removed from the groups\.yaml files\.$`)
+
+// lockedSettings are the settings of a locked group.
+var lockedSettings = groupssettings.Groups{
+	WhoCanPostMessage:     "NONE_CAN_POST",
+	WhoCanJoin:            "INVITED_CAN_JOIN",
+	WhoCanModerateMembers: "NONE",
+}
+
+// now returns the current time, it is stubbed out in tests.
+var now = time.Now
+
+// pendingDeletion returns the time after which the group with the given
+// description may be deleted, and whether it is locked pending deletion.
+func pendingDeletion(description string) (time.Time, bool) {
+	m := pendingDeletionRe.FindStringSubmatch(description)
+	if m == nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, m[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// maxDescriptionLength is the maximum length of the description of a
+// group, in characters.
+const maxDescriptionLength = 4096
+
+// withPendingDeletion returns description annotated with the time after
+// which the group may be deleted, truncating it if needed to fit.
+func withPendingDeletion(description string, after time.Time) string {
+	annotation := fmt.Sprintf(pendingDeletionFormat, after.UTC().Format(time.RFC3339))
+	if description == "" {
+		return annotation
+	}
+	if max := maxDescriptionLength - len(annotation) - 2; len([]rune(description)) > max {
+		description = string([]rune(description)[:max])
+	}
+	return description + "\n\n" + annotation
+}
+
+// withoutPendingDeletion returns description without the annotation added
+// when the group was locked.
+func withoutPendingDeletion(description string) string {
+	return pendingDeletionRe.ReplaceAllString(description, "")
+}
+
+// DeleteGroupsIfNecessary locks the existing groups that aren't in the
+// config, and deletes those that have been locked for the deletion grace
+// period of the tenant. Groups with a previous email id of a group in the
+// config are the same group, which is renamed instead.
+func (r *Reconciler) DeleteGroupsIfNecessary() error {
+	g, err := r.adminService.ListGroups()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve users in domain: %w", err)
+	}
+
+	// aggregate the errors that occurred and return them together in the end.
+	var errs []error
+
+	gracePeriod := config.GetDeletionGracePeriod()
+	for _, g := range g.Groups {
+		if isConfigured(g.Email) || r.isHeld(g.Email) {
+			continue
+		}
+
+		var err error
+		after, pending := pendingDeletion(g.Description)
+		switch {
+		case gracePeriod == 0 || pending && !now().Before(after):
+			err = r.purgeGroup(g)
+		case pending:
+			log.Printf("group %s is pending deletion after %s\n", g.Email, after.Format(time.RFC3339))
+		default:
+			err = r.lockGroup(g, now().Add(gracePeriod))
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// PurgeGroups deletes the groups with the given emails, or all groups if all
+// is true, that are locked pending deletion, without waiting for the end of
+// the deletion grace period. The deletion of sensitive groups is printed to
+// w and needs approval, by approveAll or by approval, like apply does.
+func (r *Reconciler) PurgeGroups(w io.Writer, emails []string, all bool, approveAll bool, approval *sensitiveApproval) error {
+	if r.snapshots == nil {
+		return fmt.Errorf("purging groups needs a snapshot-path to save a snapshot of them to")
+	}
+	g, err := r.adminService.ListGroups()
+	if err != nil {
+		return fmt.Errorf("unable to list groups: %w", err)
+	}
+
+	var (
+		errs   []error
+		purged []*admin.Group
+	)
+	for _, email := range emails {
+		var group *admin.Group
+		for _, g := range g.Groups {
+			if EmailAddressEquals(g.Email, email) {
+				group = g
+				break
+			}
+		}
+		switch {
+		case group == nil:
+			errs = append(errs, fmt.Errorf("group %s does not exist", email))
+		case isConfigured(group.Email):
+			errs = append(errs, fmt.Errorf("group %s is in the groups.yaml files, remove it first", group.Email))
+		default:
+			if _, pending := pendingDeletion(group.Description); !pending {
+				errs = append(errs, fmt.Errorf("group %s is not pending deletion, apply its removal from the groups.yaml files first", group.Email))
+				continue
+			}
+			purged = append(purged, group)
+		}
+	}
+	if all {
+		for _, g := range g.Groups {
+			if _, pending := pendingDeletion(g.Description); pending && !isConfigured(g.Email) {
+				purged = append(purged, g)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	if !restrictionsConfig.Sensitive.isEmpty() {
+		log.Println(" ================== Sensitive changes ==================")
+		if err := r.holdSensitivePurges(w, purged, approveAll, approval); err != nil {
+			return err
+		}
+	}
+	count := 0
+	for _, g := range purged {
+		if r.isHeld(g.Email) {
+			continue
+		}
+		if err := r.purgeGroup(g); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		count++
+	}
+	if len(r.held) > 0 {
+		errs = append(errs, fmt.Errorf("not purging sensitive groups %s as their deletion isn't approved, purge them with -approve-sensitive or an -approval-file listing them", strings.Join(r.held, ", ")))
+	}
+	log.Printf("%d groups purged", count)
+	return utilerrors.NewAggregate(errs)
+}
+
+// isConfigured reports whether the group with the given email is in the
+// config, also under a previous email id.
+func isConfigured(email string) bool {
+	for _, g := range groupsConfig.Groups {
+		if g.HasEmail(email) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockGroup locks the group g, pending deletion after the given time.
+func (r *Reconciler) lockGroup(g *admin.Group, after time.Time) error {
+	if !config.ConfirmChanges {
+		log.Printf("dry-run: would lock group %s, pending deletion after %s\n", g.Email, after.UTC().Format(time.RFC3339))
+		return nil
+	}
+
+	log.Printf("Locking group %s, pending deletion after %s", g.Email, after.UTC().Format(time.RFC3339))
+	settings := lockedSettings
+	if _, err := r.groupService.Patch(g.Email, &settings); err != nil {
+		return fmt.Errorf("unable to lock group %s: %w", g.Email, err)
+	}
+	update := admin.Group{
+		Email:       g.Email,
+		Name:        g.Name,
+		Description: withPendingDeletion(g.Description, after),
+	}
+	if _, err := r.adminService.UpdateGroup(g.Email, &update); err != nil {
+		return fmt.Errorf("unable to mark group %s as pending deletion: %w", g.Email, err)
+	}
+	log.Printf("Locked group %s\n", g.Email)
+	return nil
+}
+
+// purgeGroup saves a snapshot of the group g and deletes it. Without a
+// snapshot-path, the group is kept and a warning is logged, so that a
+// missing snapshot-path doesn't fail every run once the grace period of a
+// group is over.
+func (r *Reconciler) purgeGroup(g *admin.Group) error {
+	if r.snapshots == nil {
+		log.Printf("WARNING: not deleting group %s as no snapshot-path is configured to save a snapshot of it to\n", g.Email)
+		return nil
+	}
+	if !config.ConfirmChanges {
+		log.Printf("dry-run: would save a snapshot of group %s to %s and remove it\n", g.Email, r.snapshots)
+		return nil
+	}
+
+	snapshot, err := r.snapshotGroup(g)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s/%s.json", strings.ToLower(g.Email), now().UTC().Format("20060102T150405Z"))
+	if err := r.snapshots.Save(name, snapshot); err != nil {
+		return fmt.Errorf("unable to save a snapshot of group %s, not deleting it: %w", g.Email, err)
+	}
+	log.Printf("Saved a snapshot of group %s to %s", g.Email, name)
+
+	log.Printf("Deleting group %s", g.Email)
+	if err := r.adminService.DeleteGroup(g.Email); err != nil {
+		return fmt.Errorf("unable to remove group %s : %w", g.Email, err)
+	}
+	log.Printf("Removed group %s\n", g.Email)
+	return nil
+}
+
+// groupSnapshot is the record of a group saved before deleting it.
+type groupSnapshot struct {
+	Time     time.Time              `json:"time"`
+	Group    *admin.Group           `json:"group"`
+	Settings *groupssettings.Groups `json:"settings"`
+	Members  []*admin.Member        `json:"members"`
+}
+
+// snapshotGroup returns the snapshot of the group g, with its settings and
+// members, as JSON.
+func (r *Reconciler) snapshotGroup(g *admin.Group) ([]byte, error) {
+	settings, err := r.groupService.Get(g.Email)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve group info for group %s: %w", g.Email, err)
+	}
+	members, err := r.adminService.ListMembers(g.Email)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve members in group %s: %w", g.Email, err)
+	}
+	return json.MarshalIndent(groupSnapshot{
+		Time:     now().UTC(),
+		Group:    g,
+		Settings: settings,
+		Members:  members,
+	}, "", "  ")
+}
+
+// runPurge runs the purge command.
+func runPurge(o *options, args []string) error {
+	fs := newFlagSet("purge", o, true)
+	all := fs.Bool("all", false, "purge all groups pending deletion")
+	confirm := fs.Bool("confirm", false, "delete the groups, otherwise only show what would be done")
+	fs.BoolVar(&o.approveSensitive, "approve-sensitive", false, "also purge sensitive groups")
+	approvalFile := fs.String("approval-file", "", "also purge the sensitive groups whose deletion is listed in this approval file")
+	fs.Parse(args)
+	if fs.NArg() == 0 && !*all || fs.NArg() > 0 && *all {
+		return fmt.Errorf("purge takes the emails of groups or -all, got %q", fs.Args())
+	}
+	if *approvalFile != "" {
+		approval, err := loadSensitiveApproval(*approvalFile)
+		if err != nil {
+			return err
+		}
+		o.approval = approval
+	}
+	o.purgeGroups = fs.Args()
+	o.purgeAll = *all
+	return runTenants(o, purgeMode, *confirm)
+}
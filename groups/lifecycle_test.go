@@ -0,0 +1,406 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"k8s.io/k8s.io/groups/fake"
+)
+
+// memorySnapshotStore keeps the snapshots saved to it, keyed by name.
+type memorySnapshotStore map[string][]byte
+
+func (m memorySnapshotStore) Save(name string, content []byte) error {
+	m[name] = content
+	return nil
+}
+
+func (m memorySnapshotStore) String() string {
+	return "memory"
+}
+
+// deleteRightAway sets a deletion grace period of 0 until the test ends, so
+// that reconciling deletes the groups that aren't in the config.
+func deleteRightAway(t *testing.T) {
+	previous := config.DeletionGracePeriod
+	zero := time.Duration(0)
+	config.DeletionGracePeriod = &zero
+	t.Cleanup(func() { config.DeletionGracePeriod = previous })
+}
+
+// stubNow sets the current time to at until the test ends.
+func stubNow(t *testing.T, at time.Time) {
+	previous := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = previous })
+}
+
+// newLifecycleReconciler returns a reconciler using the augmented fake
+// clients, with group2@email.com described by description.
+func newLifecycleReconciler(description string, snapshots snapshotStore) (*Reconciler, *fake.FakeAdminServiceClient, *fake.FakeGroupServiceClient) {
+	errFunc := func(err error) bool {
+		return err != nil
+	}
+	fakeAdminClient := fake.NewAugmentedFakeAdminServiceClient()
+	fakeAdminClient.Groups["group2@email.com"].Description = description
+	fakeGroupClient := fake.NewAugmentedFakeGroupServiceClient()
+	adminSvc, _ := NewAdminServiceWithClientAndErrFunc(fakeAdminClient, errFunc)
+	groupSvc, _ := NewGroupServiceWithClientAndErrFunc(fakeGroupClient, errFunc)
+	r := &Reconciler{adminService: adminSvc, groupService: groupSvc, numWorkers: 1, snapshots: snapshots}
+	return r, fakeAdminClient, fakeGroupClient
+}
+
+func TestPendingDeletion(t *testing.T) {
+	after := time.Date(2026, 11, 18, 10, 0, 0, 0, time.UTC)
+	for _, description := range []string{"", "group2", "group2\n\nCreated via https://example.com/1", strings.Repeat("é", maxDescriptionLength)} {
+		annotated := withPendingDeletion(description, after)
+		if n := len([]rune(annotated)); n > maxDescriptionLength {
+			t.Errorf("expected at most %d characters, got %d", maxDescriptionLength, n)
+		}
+		actual, pending := pendingDeletion(annotated)
+		if !pending || !actual.Equal(after) {
+			t.Errorf("expected %q to be pending deletion after %v, got %v, %v", annotated, after, actual, pending)
+		}
+		if len(description) < maxDescriptionLength {
+			if stripped := withoutPendingDeletion(annotated); stripped != description {
+				t.Errorf("expected description %q without the annotation, got %q", description, stripped)
+			}
+		}
+	}
+
+	for _, description := range []string{
+		"group2",
+		"Pending deletion after tomorrow, as it was removed from the groups.yaml files.",
+		"Pending deletion after 2026-11-18T10:00:00Z, as it was removed from the groups.yaml files.\n\nand more",
+	} {
+		if _, pending := pendingDeletion(description); pending {
+			t.Errorf("expected %q not to be pending deletion", description)
+		}
+	}
+}
+
+func TestDeleteGroupsIfNecessary(t *testing.T) {
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	stubNow(t, at)
+	defer func() {
+		config.ConfirmChanges = false
+		config.DeletionGracePeriod = nil
+		groupsConfig = GroupsConfig{}
+	}()
+	zero := time.Duration(0)
+	hour := time.Hour
+
+	cases := []struct {
+		desc        string
+		configured  []GoogleGroup
+		description string
+		gracePeriod *time.Duration
+		noSnapshots bool
+		dryRun      bool
+		// expectedDescription is the description of group2 if it's kept.
+		expectedDescription string
+		expectedLocked      bool
+		expectedDeleted     bool
+		expectedErr         bool
+	}{
+		{
+			desc:                "states match, nothing to reconcile",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}, {EmailId: "group2@email.com"}},
+			description:         "group2",
+			expectedDescription: "group2",
+		},
+		{
+			desc:                "group2 removed, lock it for the default grace period",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:         "group2",
+			expectedDescription: withPendingDeletion("group2", at.Add(defaultDeletionGracePeriod)),
+			expectedLocked:      true,
+		},
+		{
+			desc:                "group2 removed, lock it for the configured grace period",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:         "group2",
+			gracePeriod:         &hour,
+			expectedDescription: withPendingDeletion("group2", at.Add(time.Hour)),
+			expectedLocked:      true,
+		},
+		{
+			desc:                "group2 removed, dry-run",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:         "group2",
+			dryRun:              true,
+			expectedDescription: "group2",
+		},
+		{
+			desc:                "group2 locked, grace period not over, keep it",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:         withPendingDeletion("group2", at.Add(time.Minute)),
+			expectedDescription: withPendingDeletion("group2", at.Add(time.Minute)),
+		},
+		{
+			desc:            "group2 locked, grace period over, delete it",
+			configured:      []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:     withPendingDeletion("group2", at),
+			expectedDeleted: true,
+		},
+		{
+			desc:                "group2 locked, grace period over, dry-run",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:         withPendingDeletion("group2", at),
+			dryRun:              true,
+			expectedDescription: withPendingDeletion("group2", at),
+		},
+		{
+			desc:                "group2 locked, grace period over, no snapshot-path, keep it",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:         withPendingDeletion("group2", at),
+			noSnapshots:         true,
+			expectedDescription: withPendingDeletion("group2", at),
+		},
+		{
+			desc:            "group2 removed, no grace period, delete it",
+			configured:      []GoogleGroup{{EmailId: "group1@email.com"}},
+			description:     "group2",
+			gracePeriod:     &zero,
+			expectedDeleted: true,
+		},
+		{
+			desc:                "group2 is a previous email id, keep it",
+			configured:          []GoogleGroup{{EmailId: "group1@email.com"}, {EmailId: "group3@email.com", PreviousEmailIds: []string{"Group2@email.com"}}},
+			description:         "group2",
+			expectedDescription: "group2",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			config.ConfirmChanges = !c.dryRun
+			config.DeletionGracePeriod = c.gracePeriod
+			groupsConfig.Groups = c.configured
+			snapshots := memorySnapshotStore{}
+			r, fakeAdminClient, fakeGroupClient := newLifecycleReconciler(c.description, snapshots)
+			if c.noSnapshots {
+				r.snapshots = nil
+			}
+
+			err := r.DeleteGroupsIfNecessary()
+			if c.expectedErr != (err != nil) {
+				t.Errorf("expected error %v, got %v", c.expectedErr, err)
+			}
+
+			if _, ok := fakeAdminClient.Groups["group1@email.com"]; !ok {
+				t.Errorf("expected group1@email.com to be kept")
+			}
+			group2, ok := fakeAdminClient.Groups["group2@email.com"]
+			if ok == c.expectedDeleted {
+				t.Fatalf("expected group2@email.com to be deleted: %v, got: %v", c.expectedDeleted, !ok)
+			}
+			if c.expectedDeleted {
+				snapshot, ok := snapshots["group2@email.com/20261019T100000Z.json"]
+				if !ok || len(snapshots) != 1 {
+					t.Fatalf("expected a snapshot of group2@email.com, got %v", snapshots)
+				}
+				var s groupSnapshot
+				if err := json.Unmarshal(snapshot, &s); err != nil {
+					t.Fatal(err)
+				}
+				if s.Group.Email != "group2@email.com" || len(s.Members) != 2 || s.Settings.WhoCanModerateMembers != "OWNERS_ONLY" {
+					t.Errorf("unexpected snapshot: %s", snapshot)
+				}
+				return
+			}
+			if len(snapshots) != 0 {
+				t.Errorf("unexpected snapshots: %v", snapshots)
+			}
+			if group2.Description != c.expectedDescription {
+				t.Errorf("expected description %q, got %q", c.expectedDescription, group2.Description)
+			}
+			if group2.Name != "group2" {
+				t.Errorf("expected name group2, got %q", group2.Name)
+			}
+			if len(fakeAdminClient.Members["group2@email.com"]) != 2 {
+				t.Errorf("expected the members of group2@email.com to be kept, got %v", fakeAdminClient.Members["group2@email.com"])
+			}
+			locked := fakeGroupClient.GsGroups["group2@email.com"].WhoCanPostMessage == lockedSettings.WhoCanPostMessage
+			if locked != c.expectedLocked {
+				t.Errorf("expected group2@email.com to be locked: %v, got settings %+v", c.expectedLocked, fakeGroupClient.GsGroups["group2@email.com"])
+			}
+		})
+	}
+}
+
+func TestPurgeGroups(t *testing.T) {
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	stubNow(t, at)
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+		restrictionsConfig = RestrictionsConfig{}
+	}()
+	groupsConfig.Groups = []GoogleGroup{{EmailId: "group1@email.com"}}
+	locked := withPendingDeletion("group2", at.Add(time.Hour))
+
+	cases := []struct {
+		desc        string
+		description string
+		emails      []string
+		all         bool
+		// sensitive makes group2 a sensitive group.
+		sensitive bool
+		// sensitivePath makes group2 a group of a sensitive path.
+		sensitivePath   bool
+		approveAll      bool
+		noSnapshots     bool
+		expectedDeleted bool
+		expectedErr     string
+	}{
+		{
+			desc:            "purge a locked group before the end of the grace period",
+			description:     locked,
+			emails:          []string{"Group2@email.com"},
+			expectedDeleted: true,
+		},
+		{
+			desc:            "purge all locked groups",
+			description:     locked,
+			all:             true,
+			expectedDeleted: true,
+		},
+		{
+			desc:        "all groups, none locked",
+			description: "group2",
+			all:         true,
+		},
+		{
+			desc:        "group that isn't locked",
+			description: "group2",
+			emails:      []string{"group2@email.com"},
+			expectedErr: "group group2@email.com is not pending deletion",
+		},
+		{
+			desc:        "group in the config",
+			description: locked,
+			emails:      []string{"group1@email.com", "group2@email.com"},
+			expectedErr: "group group1@email.com is in the groups.yaml files",
+		},
+		{
+			desc:        "group that doesn't exist",
+			description: locked,
+			emails:      []string{"group3@email.com"},
+			expectedErr: "group group3@email.com does not exist",
+		},
+		{
+			desc:        "sensitive group without approval",
+			description: locked,
+			emails:      []string{"group2@email.com"},
+			sensitive:   true,
+			expectedErr: "not purging sensitive groups group2@email.com",
+		},
+		{
+			desc:          "group of a sensitive path without approval",
+			description:   locked,
+			emails:        []string{"group2@email.com"},
+			sensitivePath: true,
+			expectedErr:   "not purging sensitive groups group2@email.com",
+		},
+		{
+			desc:            "sensitive group approved",
+			description:     locked,
+			emails:          []string{"group2@email.com"},
+			sensitive:       true,
+			approveAll:      true,
+			expectedDeleted: true,
+		},
+		{
+			desc:        "no snapshot-path",
+			description: locked,
+			emails:      []string{"group2@email.com"},
+			noSnapshots: true,
+			expectedErr: "purging groups needs a snapshot-path",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			restrictionsConfig = RestrictionsConfig{}
+			if c.sensitive {
+				restrictionsConfig.Sensitive.GroupsRe = []*regexp.Regexp{regexp.MustCompile("^group2@")}
+			}
+			if c.sensitivePath {
+				restrictionsConfig.Sensitive.Paths = []string{"committee-foo/groups.yaml"}
+				restrictionsConfig.Restrictions = []Restriction{{Path: "committee-foo/groups.yaml", AllowedGroupsRe: []*regexp.Regexp{regexp.MustCompile("^group2@")}}}
+			}
+			snapshots := memorySnapshotStore{}
+			r, fakeAdminClient, _ := newLifecycleReconciler(c.description, snapshots)
+			if c.noSnapshots {
+				r.snapshots = nil
+			}
+
+			err := r.PurgeGroups(io.Discard, c.emails, c.all, c.approveAll, nil)
+			if c.expectedErr == "" && err != nil || c.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), c.expectedErr)) {
+				t.Errorf("expected error %q, got %v", c.expectedErr, err)
+			}
+			if _, ok := fakeAdminClient.Groups["group2@email.com"]; ok == c.expectedDeleted {
+				t.Errorf("expected group2@email.com to be deleted: %v, got: %v", c.expectedDeleted, !ok)
+			}
+			if _, ok := snapshots["group2@email.com/20261019T100000Z.json"]; ok != c.expectedDeleted {
+				t.Errorf("expected a snapshot of group2@email.com: %v, got: %v", c.expectedDeleted, snapshots)
+			}
+			if _, ok := fakeAdminClient.Groups["group1@email.com"]; !ok {
+				t.Errorf("expected group1@email.com to be kept")
+			}
+		})
+	}
+}
+
+func TestReconcileLockedGroup(t *testing.T) {
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	stubNow(t, at)
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+	}()
+	groupsConfig.Groups = []GoogleGroup{
+		{EmailId: "group1@email.com", Managers: []string{"m2-group1@email.com"}, Members: []string{"m1-group1@email.com"}},
+		{EmailId: "group2@email.com", Owners: []string{"m2-group2@email.com"}, Members: []string{"m1-group2@email.com"}},
+	}
+
+	r, fakeAdminClient, fakeGroupClient := newLifecycleReconciler(withPendingDeletion("group2", at.Add(time.Hour)), memorySnapshotStore{})
+	settings := lockedSettings
+	fakeGroupClient.GsGroups["group2@email.com"] = &settings
+
+	if err := r.ReconcileGroups(groupsConfig.Groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := fakeAdminClient.Groups["group2@email.com"].Description; d != "group2" {
+		t.Errorf("expected the description of group2@email.com to be restored, got %q", d)
+	}
+	expected := groupssettings.Groups{WhoCanPostMessage: "ALL_MEMBERS_CAN_POST", WhoCanJoin: "INVITED_CAN_JOIN", WhoCanModerateMembers: "OWNERS_AND_MANAGERS"}
+	actual := fakeGroupClient.GsGroups["group2@email.com"]
+	if actual.WhoCanPostMessage != expected.WhoCanPostMessage || actual.WhoCanJoin != expected.WhoCanJoin || actual.WhoCanModerateMembers != expected.WhoCanModerateMembers {
+		t.Errorf("expected the settings of group2@email.com to be unlocked, got %+v", actual)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/k8s.io/groups/groupsfile"
+)
+
+// fieldOfRole returns the field of a group listing the addresses with role,
+// given in any case.
+func fieldOfRole(role string) (string, error) {
+	for field, r := range roleOfField {
+		if strings.EqualFold(r, role) {
+			return field, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q, must be one of %s, %s or %s", role, OwnerRole, ManagerRole, MemberRole)
+}
+
+// editGroup edits the groups.yaml file defining group among groups, loaded
+// from rootDir with restrictions, with edit. The edited file is checked like
+// GroupsConfig.Load does, and only written if it is valid, its groups can
+// still be merged with the others and they keep enough owners and managers.
+// It returns the path of the file.
+func editGroup(groups []GoogleGroup, rootDir string, restrictions *RestrictionsConfig, group string, edit func(f *groupsfile.File) error) (string, error) {
+	var path string
+	for _, g := range groups {
+		if EmailAddressEquals(g.EmailId, group) {
+			path = g.Position().Path
+		}
+	}
+	if path == "" {
+		return "", fmt.Errorf("group %s is not defined in any groups.yaml file", group)
+	}
+
+	f, err := groupsfile.Read(path)
+	if err != nil {
+		return "", err
+	}
+	f.Equal = EmailAddressEquals
+	if err := edit(f); err != nil {
+		return "", err
+	}
+
+	edited, err := parseGroupsFile(path, f.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("refusing the edit: %w", err)
+	}
+	var others []GoogleGroup
+	for _, g := range groups {
+		if g.Position().Path != path {
+			others = append(others, g)
+		}
+	}
+	merged, err := mergeGroups(others, edited, restrictions.GetRestrictionForPath(path, rootDir))
+	if err != nil {
+		return "", fmt.Errorf("refusing the edit: %w", err)
+	}
+	if errs := checkOwnersAndManagers(edited, merged, restrictions, rootDir); len(errs) > 0 {
+		return "", fmt.Errorf("refusing the edit: %w", utilerrors.NewAggregate(errs))
+	}
+	return path, f.Write()
+}
+
+// runMember implements the member command, editing the owners, managers and
+// members of a group in the groups.yaml file defining it:
+//
+//	member add [-role MEMBER] <group> <email>
+//	member remove <group> <email>
+//	member set-role <group> <email> <role>
+func runMember(o *options, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("member takes add, remove or set-role")
+	}
+	fs := newFlagSet("member "+args[0], o, false)
+	var (
+		usage string
+		nargs int
+		edit  func(f *groupsfile.File, group, email string, args []string) (string, error)
+	)
+	switch args[0] {
+	case "add":
+		role := fs.String("role", MemberRole, "the role of the new member: OWNER, MANAGER or MEMBER")
+		usage, nargs = "a group and an email", 2
+		edit = func(f *groupsfile.File, group, email string, _ []string) (string, error) {
+			field, err := fieldOfRole(*role)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("added %s to %s as %s", email, group, roleOfField[field]), f.Add(group, field, email)
+		}
+	case "remove":
+		usage, nargs = "a group and an email", 2
+		edit = func(f *groupsfile.File, group, email string, _ []string) (string, error) {
+			fields, err := f.Remove(group, email)
+			if err == nil && len(fields) == 0 {
+				err = fmt.Errorf("group %s doesn't list %s", group, email)
+			}
+			return fmt.Sprintf("removed %s from %s", email, group), err
+		}
+	case "set-role":
+		usage, nargs = "a group, an email and a role", 3
+		edit = func(f *groupsfile.File, group, email string, args []string) (string, error) {
+			field, err := fieldOfRole(args[0])
+			if err != nil {
+				return "", err
+			}
+			_, err = f.SetField(group, email, field)
+			return fmt.Sprintf("set the role of %s in %s to %s", email, group, roleOfField[field]), err
+		}
+	default:
+		return fmt.Errorf("unknown member command %q, must be add, remove or set-role", args[0])
+	}
+	fs.Parse(args[1:])
+	if fs.NArg() != nargs || !strings.Contains(fs.Arg(1), "@") {
+		return fmt.Errorf("member %s takes %s, got %q", args[0], usage, fs.Args())
+	}
+	group, email := fs.Arg(0), fs.Arg(1)
+
+	if err := config.Load(o.configFile, false); err != nil {
+		return err
+	}
+	for _, t := range config.Tenants {
+		if err := loadTenant(t); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		if !containsGroup(groupsConfig.Groups, group) {
+			continue
+		}
+		var done string
+		path, err := editGroup(groupsConfig.Groups, config.GroupsPath, &restrictionsConfig, group, func(f *groupsfile.File) error {
+			var err error
+			done, err = edit(f, group, email, fs.Args()[2:])
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", relativePath(path), done)
+		return nil
+	}
+	return fmt.Errorf("group %s is not defined in any groups.yaml file", group)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"k8s.io/k8s.io/groups/groupsfile"
+)
+
+func TestEditGroup(t *testing.T) {
+	content := `groups:
+  - email-id: group1@example.com
+    owners:
+      - jane@example.com
+    members:
+      # Approvers.
+      - john@example.com
+`
+	cases := []struct {
+		desc         string
+		edit         func(f *groupsfile.File) error
+		restrictions RestrictionsConfig
+		expected     string
+		expectedErr  bool
+	}{
+		{
+			desc: "add",
+			edit: func(f *groupsfile.File) error {
+				return f.Add("group1@example.com", groupsfile.MembersField, "alice@example.com")
+			},
+			expected: `groups:
+  - email-id: group1@example.com
+    owners:
+      - jane@example.com
+    members:
+      # Approvers.
+      - alice@example.com
+      - john@example.com
+`,
+		},
+		{
+			desc: "set role of an address spelled differently",
+			edit: func(f *groupsfile.File) error {
+				_, err := f.SetField("group1@example.com", "John@example.com", groupsfile.ManagersField)
+				return err
+			},
+			expected: `groups:
+  - email-id: group1@example.com
+    owners:
+      - jane@example.com
+    managers:
+      - John@example.com
+`,
+		},
+		{
+			desc: "invalid address",
+			edit: func(f *groupsfile.File) error {
+				return f.Add("group1@example.com", groupsfile.MembersField, "not an address")
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "group not allowed in the file",
+			edit: func(f *groupsfile.File) error {
+				return f.Add("group1@example.com", groupsfile.MembersField, "alice@example.com")
+			},
+			restrictions: RestrictionsConfig{Restrictions: []Restriction{{
+				Path:            "groups.yaml",
+				AllowedGroupsRe: []*regexp.Regexp{regexp.MustCompile("^group2@example.com$")},
+			}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			path := writeGroupsFile(t, content)
+			var gc GroupsConfig
+			if err := gc.Load(filepath.Dir(path), &RestrictionsConfig{}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := editGroup(gc.Groups, filepath.Dir(path), &c.restrictions, "group1@example.com", c.edit)
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			written, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := c.expected
+			if expected == "" {
+				expected = content
+			}
+			if actual := string(written); actual != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestFieldOfRole(t *testing.T) {
+	for role, expected := range map[string]string{
+		"OWNER":   "owners",
+		"manager": "managers",
+		"Member":  "members",
+	} {
+		actual, err := fieldOfRole(role)
+		if err != nil || !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected field %q for role %s, got %q, %v", expected, role, actual, err)
+		}
+	}
+	if _, err := fieldOfRole("ADMIN"); err == nil {
+		t.Errorf("expected an error for an unknown role")
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/net/context"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/k8s.io/groups/groupsfile"
+)
+
+// Offboarding is the removal of a person from a group.
+type Offboarding struct {
+	// Group is the email of the group.
+	Group string
+	// Path is the groups.yaml file defining the group.
+	Path string
+	// Roles are the roles the person was removed from.
+	Roles []string
+	// Unmanaged is set if the person was an owner or manager of the group
+	// and the group is left without any.
+	Unmanaged bool
+}
+
+func (o Offboarding) String() string {
+	s := fmt.Sprintf("%s: removed from %s (%s)", relativePath(o.Path), o.Group, strings.Join(o.Roles, ", "))
+	if o.Unmanaged {
+		s += ", WARNING: the group is left without owners or managers"
+	}
+	return s
+}
+
+// offboardGroups removes the addresses of the person with the given email,
+// according to EmailAddressEquals, from groups in the groups.yaml files
+// defining them. It returns the groups the person was removed from. No file
+// is written if any group can't be edited.
+func offboardGroups(groups []GoogleGroup, email string) ([]Offboarding, error) {
+	files := map[string]*groupsfile.File{}
+	res, err := editOffboarding(files, groups, email)
+	if err != nil {
+		return nil, err
+	}
+	return res, writeGroupsFiles(files)
+}
+
+// editOffboarding removes the addresses of the person with the given email
+// from groups in files, the groups.yaml files being edited by path, which
+// are read as needed. Nothing is written, so that no file is edited unless
+// all of them can be.
+func editOffboarding(files map[string]*groupsfile.File, groups []GoogleGroup, email string) ([]Offboarding, error) {
+	var (
+		res  []Offboarding
+		errs []error
+	)
+	for _, g := range groups {
+		if !listsAddress(g, email) {
+			continue
+		}
+		path := g.Position().Path
+		if path == "" {
+			errs = append(errs, fmt.Errorf("group %s wasn't read from a file", g.EmailId))
+			continue
+		}
+		f, ok := files[path]
+		if !ok {
+			var err error
+			if f, err = groupsfile.Read(path); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			f.Equal = EmailAddressEquals
+			files[path] = f
+		}
+		fields, err := f.Remove(g.EmailId, email)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		o := Offboarding{Group: g.EmailId, Path: path}
+		administered := false
+		for _, field := range fields {
+			r := roleOfField[field]
+			o.Roles = append(o.Roles, r)
+			administered = administered || r == OwnerRole || r == ManagerRole
+		}
+		if administered {
+			o.Unmanaged = true
+			for _, m := range append(append([]string{}, g.Owners...), g.Managers...) {
+				if !EmailAddressEquals(m, email) {
+					o.Unmanaged = false
+					break
+				}
+			}
+		}
+		res = append(res, o)
+	}
+	return res, utilerrors.NewAggregate(errs)
+}
+
+// writeGroupsFiles writes files back to their paths.
+func writeGroupsFiles(files map[string]*groupsfile.File) error {
+	var errs []error
+	for _, f := range files {
+		if err := f.Write(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// listsAddress reports whether g lists email as an owner, manager or member
+// or has a delivery setting or schedule for it.
+func listsAddress(g GoogleGroup, email string) bool {
+	for _, m := range append(append(append([]string{}, g.Owners...), g.Managers...), g.Members...) {
+		if EmailAddressEquals(m, email) {
+			return true
+		}
+	}
+	_, scheduled := g.ScheduleFor(email)
+	return g.DeliveryFor(email) != "" || scheduled
+}
+
+// runOffboard implements the offboard command: it removes a person from
+// every groups.yaml file of the config, then prints the plan of reconciling
+// the groups they were removed from, unless -plan=false.
+func runOffboard(o *options, args []string) error {
+	fs := newFlagSet("offboard", o, true)
+	plan := fs.Bool("plan", true, "print the plan of reconciling the edited groups, which needs credentials")
+	fs.Parse(args)
+	if fs.NArg() != 1 || !strings.Contains(fs.Arg(0), "@") {
+		return fmt.Errorf("offboard takes an email, got %q", fs.Args())
+	}
+	email := fs.Arg(0)
+
+	if err := config.Load(o.configFile, false); err != nil {
+		return err
+	}
+	// Edit the files of every tenant before writing any, so that a failure
+	// leaves them all untouched.
+	var (
+		offboardings []Offboarding
+		errs         []error
+		files        = map[string]*groupsfile.File{}
+		offboarded   = map[string][]string{}
+	)
+	for _, t := range config.Tenants {
+		if err := loadTenant(t); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		res, err := editOffboarding(files, groupsConfig.Groups, email)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+			continue
+		}
+		offboardings = append(offboardings, res...)
+		for _, ob := range res {
+			offboarded[t.Name] = append(offboarded[t.Name], ob.Group)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("no file was edited: %w", utilerrors.NewAggregate(errs))
+	}
+	if err := writeGroupsFiles(files); err != nil {
+		return err
+	}
+	for _, ob := range offboardings {
+		fmt.Println(ob)
+	}
+	if len(offboarded) == 0 {
+		log.Printf("%s is not in any group", email)
+		return nil
+	}
+	if !*plan {
+		return nil
+	}
+
+	for _, t := range config.Tenants {
+		if len(offboarded[t.Name]) == 0 {
+			continue
+		}
+		opts := *o
+		opts.selector = groupSelector{groups: offboarded[t.Name]}
+		if report := reconcileTenant(context.Background(), t, &opts, reconcileMode); report.err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, report.err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeGroupsFile writes content to a groups.yaml file in a new temporary
+// directory and returns its path.
+func writeGroupsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "groups.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOffboardGroups(t *testing.T) {
+	path := writeGroupsFile(t, `groups:
+  - email-id: group1@example.com
+    owners:
+      - jane@example.com
+    members:
+      - john@example.com
+  - email-id: group2@example.com
+    owners:
+      - john@example.com
+    managers:
+      - Jane@example.com
+  - email-id: group3@example.com
+    members:
+      - jane@example.com
+`)
+	var gc GroupsConfig
+	if err := gc.Load(filepath.Dir(path), &RestrictionsConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := offboardGroups(gc.Groups, "jane@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Offboarding{
+		{Group: "group1@example.com", Path: path, Roles: []string{OwnerRole}, Unmanaged: true},
+		{Group: "group2@example.com", Path: path, Roles: []string{ManagerRole}},
+		{Group: "group3@example.com", Path: path, Roles: []string{MemberRole}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected offboardings %+v, got %+v", expected, actual)
+	}
+
+	var after GroupsConfig
+	if err := after.Load(filepath.Dir(path), &RestrictionsConfig{}); err != nil {
+		t.Fatalf("error loading the edited file: %v", err)
+	}
+	if memberships := findMemberships(after.Groups, personMatcher("jane@example.com", nil)); len(memberships) != 0 {
+		t.Errorf("expected no memberships left, got %+v", memberships)
+	}
+}
+
+func TestOffboardGroupsWritesNothingOnError(t *testing.T) {
+	const editable = `groups:
+  - email-id: group1@example.com
+    members:
+      - jane@example.com
+`
+	// Flow style lists can't be edited.
+	const uneditable = `groups:
+  - email-id: group2@example.com
+    members: [jane@example.com]
+`
+	var groups []GoogleGroup
+	paths := map[string]string{}
+	for _, content := range []string{editable, uneditable} {
+		path := writeGroupsFile(t, content)
+		paths[path] = content
+		var gc GroupsConfig
+		if err := gc.Load(filepath.Dir(path), &RestrictionsConfig{}); err != nil {
+			t.Fatal(err)
+		}
+		groups = append(groups, gc.Groups...)
+	}
+
+	if _, err := offboardGroups(groups, "jane@example.com"); err == nil {
+		t.Fatal("expected an error")
+	}
+	for path, content := range paths {
+		actual, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != content {
+			t.Errorf("expected %s to be left untouched, got:\n%s", path, actual)
+		}
+	}
+}
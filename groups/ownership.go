@@ -0,0 +1,113 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// serviceAccountDomain is the domain of the addresses of Google Cloud
+// service accounts, e.g. <name>@<project>.iam.gserviceaccount.com.
+const serviceAccountDomain = ".gserviceaccount.com"
+
+// GetMinOwnersAndManagers returns the minimum number of owners and managers
+// who are people of the groups defined at path, relative to rootDir.
+func (rc *RestrictionsConfig) GetMinOwnersAndManagers(path, rootDir string) int {
+	if r := rc.GetRestrictionForPath(path, rootDir); r.MinOwnersAndManagers != nil {
+		return *r.MinOwnersAndManagers
+	}
+	return rc.MinOwnersAndManagers
+}
+
+// isPerson reports whether email is the address of a person rather than of
+// one of groups, a service account or the bot-id. Groups that aren't defined
+// in groups.yaml files can't be told apart from people without the APIs.
+func isPerson(email string, groups []GoogleGroup) bool {
+	if strings.HasSuffix(strings.ToLower(email), serviceAccountDomain) {
+		return false
+	}
+	if config.BotID != "" && EmailAddressEquals(email, config.BotID) {
+		return false
+	}
+	return !containsGroup(groups, email)
+}
+
+// peopleOwningGroup returns the distinct owners and managers of g who are
+// people, see isPerson.
+func peopleOwningGroup(g GoogleGroup, groups []GoogleGroup) []string {
+	var people []string
+	seen := map[string]bool{}
+	for _, m := range append(append([]string{}, g.Owners...), g.Managers...) {
+		if !isPerson(m, groups) || seen[CanonicalEmail(m)] {
+			continue
+		}
+		seen[CanonicalEmail(m)] = true
+		people = append(people, m)
+	}
+	return people
+}
+
+// checkOwnersAndManagers checks that each of checked, among all groups
+// loaded from rootDir, has the minimum number of owners and managers who
+// are people set by restrictions for its path.
+func checkOwnersAndManagers(checked, groups []GoogleGroup, restrictions *RestrictionsConfig, rootDir string) []error {
+	var errs []error
+	for _, g := range checked {
+		min := restrictions.GetMinOwnersAndManagers(g.Position().Path, rootDir)
+		people := peopleOwningGroup(g, groups)
+		if len(people) >= min {
+			continue
+		}
+		switch {
+		case len(g.Owners)+len(g.Managers) == 0:
+			errs = append(errs, errorAt(g.Position(), "group %s has no owners or managers, at least %d people are required", g.EmailId, min))
+		case len(people) == 0:
+			errs = append(errs, errorAt(g.Position(), "group %s is only owned and managed by groups or service accounts, at least %d people are required", g.EmailId, min))
+		default:
+			errs = append(errs, errorAt(g.Position(), "group %s has %d owners and managers who are people, at least %d are required", g.EmailId, len(people), min))
+		}
+	}
+	return errs
+}
+
+// CheckBotID checks that the groups listing the bot-id list it as an owner,
+// so that reconciling them doesn't demote it and lock it out of the groups
+// it needs to own.
+func (t *Tenant) CheckBotID(groups []GoogleGroup) error {
+	if t.BotID == "" {
+		return nil
+	}
+	var errs []error
+	for _, g := range groups {
+		for _, list := range []struct {
+			role    string
+			members []string
+		}{
+			{ManagerRole, g.Managers},
+			{MemberRole, g.Members},
+		} {
+			for i, m := range list.members {
+				if EmailAddressEquals(m, t.BotID) {
+					errs = append(errs, errorAt(g.MemberPosition(list.role, i), "bot-id %s of tenant %s must be an owner of group %s, not a %s", m, t.Name, g.EmailId, list.role))
+				}
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
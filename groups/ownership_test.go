@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckOwnersAndManagers(t *testing.T) {
+	path := writeGroupsFile(t, `groups:
+  - email-id: bots@example.com
+  - email-id: leads@example.com
+    owners:
+      - jane@example.com
+    managers:
+      - j.a.n.e@example.com
+      - john@example.com
+  - email-id: nested@example.com
+    owners:
+      - leads@example.com
+      - bot@project.iam.gserviceaccount.com
+  - email-id: single@example.com
+    owners:
+      - Jane@example.com
+      - bot@example.com
+`)
+	rootDir := filepath.Dir(path)
+	two := 2
+	restrictions := &RestrictionsConfig{
+		MinOwnersAndManagers: 1,
+		Restrictions: []Restriction{
+			{Path: "groups.yaml", AllowedGroupsRe: defaultRestriction.AllowedGroupsRe, MinOwnersAndManagers: &two},
+		},
+	}
+	config.BotID = "bot@example.com"
+	defer func() { config.BotID = "" }()
+
+	var gc GroupsConfig
+	err := gc.Load(rootDir, restrictions)
+	if err == nil {
+		t.Fatal("expected an error loading groups without enough owners and managers")
+	}
+	expected := []string{
+		"groups.yaml:2:5: group bots@example.com has no owners or managers, at least 2 people are required",
+		"groups.yaml:9:5: group nested@example.com is only owned and managed by groups or service accounts, at least 2 people are required",
+		"groups.yaml:13:5: group single@example.com has 1 owners and managers who are people, at least 2 are required",
+	}
+	for _, e := range expected {
+		if !strings.Contains(err.Error(), e) {
+			t.Errorf("expected error %q, got: %v", e, err)
+		}
+	}
+	if strings.Contains(err.Error(), "leads@example.com has") {
+		t.Errorf("expected leads@example.com to have enough owners and managers, got: %v", err)
+	}
+
+	restrictions.Restrictions[0].MinOwnersAndManagers = nil
+	restrictions.MinOwnersAndManagers = 0
+	if err := (&GroupsConfig{}).Load(rootDir, restrictions); err != nil {
+		t.Errorf("unexpected error without a minimum: %v", err)
+	}
+}
+
+func TestCheckBotID(t *testing.T) {
+	tenant := Tenant{Name: "default", BotID: "bot@example.com"}
+	groups := []GoogleGroup{
+		{EmailId: "group1@example.com", Owners: []string{"bot@example.com"}},
+		{EmailId: "group2@example.com", Managers: []string{"Bot@example.com"}},
+		{EmailId: "group3@example.com", Members: []string{"bot@example.com"}},
+	}
+	err := tenant.CheckBotID(groups)
+	if err == nil {
+		t.Fatal("expected an error for a bot-id that isn't an owner")
+	}
+	for _, e := range []string{
+		"bot-id Bot@example.com of tenant default must be an owner of group group2@example.com, not a MANAGER",
+		"bot-id bot@example.com of tenant default must be an owner of group group3@example.com, not a MEMBER",
+	} {
+		if !strings.Contains(err.Error(), e) {
+			t.Errorf("expected error %q, got: %v", e, err)
+		}
+	}
+	if strings.Contains(err.Error(), "group1@example.com") {
+		t.Errorf("unexpected error for a bot-id that is an owner: %v", err)
+	}
+}
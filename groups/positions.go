@@ -0,0 +1,244 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Position is a location in a groups.yaml file.
+type Position struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+// IsValid reports whether the position is known, i.e. whether the value it
+// belongs to was read from a file.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Path, p.Line, p.Column)
+}
+
+// PositionError is an error about the value at Pos.
+type PositionError struct {
+	Pos Position
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Pos, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// errorAt returns an error formatted like fmt.Errorf, prefixed with pos if
+// it is valid.
+func errorAt(pos Position, format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	if !pos.IsValid() {
+		return err
+	}
+	return &PositionError{Pos: pos, Err: err}
+}
+
+// roleOfField maps the fields of a GoogleGroup listing addresses to the role
+// of those addresses.
+var roleOfField = map[string]string{
+	"owners":   OwnerRole,
+	"managers": ManagerRole,
+	"members":  MemberRole,
+}
+
+// groupSource records where a GoogleGroup was defined.
+type groupSource struct {
+	pos Position
+	// members holds the position of each item of the owners, managers and
+	// members lists, keyed by role.
+	members map[string][]Position
+	// delivery holds the position of each key of the delivery map.
+	delivery map[string]Position
+	// schedule holds the position of each key of the schedule map.
+	schedule map[string]Position
+	// fields holds the fields the group sets, see GoogleGroup.specifies.
+	fields map[string]bool
+}
+
+// UnmarshalYAML decodes a GoogleGroup and records the fields it sets and the
+// position of the group and of each of its owners, managers, members,
+// delivery settings and schedules. The
+// path of the positions is set by GroupsConfig.Load.
+func (g *GoogleGroup) UnmarshalYAML(node *yaml.Node) error {
+	type plain GoogleGroup
+	if err := node.Decode((*plain)(g)); err != nil {
+		return err
+	}
+
+	g.source = groupSource{
+		pos:      Position{Line: node.Line, Column: node.Column},
+		members:  map[string][]Position{},
+		delivery: map[string]Position{},
+		schedule: map[string]Position{},
+		fields:   map[string]bool{},
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		g.source.fields[key.Value] = true
+		switch key.Value {
+		case "owners", "managers", "members":
+			role := roleOfField[key.Value]
+			for _, item := range value.Content {
+				g.source.members[role] = append(g.source.members[role], Position{Line: item.Line, Column: item.Column})
+			}
+		case "delivery":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				g.source.delivery[value.Content[j].Value] = Position{Line: value.Content[j].Line, Column: value.Content[j].Column}
+			}
+		case "schedule":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				g.source.schedule[value.Content[j].Value] = Position{Line: value.Content[j].Line, Column: value.Content[j].Column}
+			}
+		}
+	}
+	return nil
+}
+
+// specifies reports whether g sets field, one of "name", "description" or
+// "aliases", even if to an empty value. For groups that weren't read from a
+// file, the name and description count as set if they aren't empty, and the
+// aliases if they aren't nil.
+func (g GoogleGroup) specifies(field string) bool {
+	if g.source.fields != nil {
+		return g.source.fields[field]
+	}
+	switch field {
+	case "name":
+		return g.Name != ""
+	case "description":
+		return g.Description != ""
+	case "aliases":
+		return g.Aliases != nil
+	}
+	return false
+}
+
+// setPath sets the path of the file the group was read from.
+func (g *GoogleGroup) setPath(path string) {
+	g.source.pos.Path = path
+}
+
+// Position returns where the group was defined, if it was read from a file.
+func (g GoogleGroup) Position() Position {
+	return g.source.pos
+}
+
+// MemberPosition returns where the i-th address listed under role (one of
+// OwnerRole, ManagerRole or MemberRole) was defined, if it was read from a
+// file.
+func (g GoogleGroup) MemberPosition(role string, i int) Position {
+	positions := g.source.members[role]
+	if i < 0 || i >= len(positions) {
+		return Position{}
+	}
+	pos := positions[i]
+	pos.Path = g.source.pos.Path
+	return pos
+}
+
+// DeliveryPosition returns where the delivery setting of email was defined,
+// if it was read from a file.
+func (g GoogleGroup) DeliveryPosition(email string) Position {
+	pos, ok := g.source.delivery[email]
+	if !ok {
+		return Position{}
+	}
+	pos.Path = g.source.pos.Path
+	return pos
+}
+
+// positionErrors returns the PositionErrors within err, looking into wrapped
+// errors and aggregates.
+func positionErrors(err error) []*PositionError {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*PositionError); ok {
+		return []*PositionError{pe}
+	}
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		var res []*PositionError
+		for _, e := range agg.Errors() {
+			res = append(res, positionErrors(e)...)
+		}
+		return res
+	}
+	return positionErrors(errors.Unwrap(err))
+}
+
+// githubAnnotations formats the PositionErrors within err as GitHub Actions
+// workflow commands, so they are shown inline on the lines of a pull request
+// they refer to. Paths are made relative to the working directory.
+//
+// See https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#setting-an-error-message
+func githubAnnotations(err error) []string {
+	var res []string
+	for _, pe := range positionErrors(err) {
+		path := relativePath(pe.Pos.Path)
+		msg := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(pe.Err.Error())
+		res = append(res, fmt.Sprintf("::error file=%s,line=%d,col=%d::%s", path, pe.Pos.Line, pe.Pos.Column, msg))
+	}
+	return res
+}
+
+// relativePath returns path relative to the working directory if it is
+// under it, or path otherwise.
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// SchedulePosition returns where the schedule of email was defined, if it
+// was read from a file.
+func (g GoogleGroup) SchedulePosition(email string) Position {
+	pos, ok := g.source.schedule[email]
+	if !ok {
+		return Position{}
+	}
+	pos.Path = g.source.pos.Path
+	return pos
+}
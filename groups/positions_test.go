@@ -0,0 +1,220 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestGoogleGroupPositions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "groups.yaml")
+	content := `groups:
+  - email-id: group1@example.com
+    owners:
+      - owner@example.com
+    members:
+      - member1@example.com
+      - member2@example.com
+    delivery:
+      member2@example.com: DIGEST
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gc GroupsConfig
+	if err := gc.Load(dir, &RestrictionsConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gc.Groups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(gc.Groups))
+	}
+	g := gc.Groups[0]
+
+	for _, c := range []struct {
+		desc     string
+		actual   Position
+		expected Position
+	}{
+		{"group", g.Position(), Position{Path: path, Line: 2, Column: 5}},
+		{"owner", g.MemberPosition(OwnerRole, 0), Position{Path: path, Line: 4, Column: 9}},
+		{"second member", g.MemberPosition(MemberRole, 1), Position{Path: path, Line: 7, Column: 9}},
+		{"missing manager", g.MemberPosition(ManagerRole, 0), Position{}},
+		{"delivery", g.DeliveryPosition("member2@example.com"), Position{Path: path, Line: 9, Column: 7}},
+	} {
+		if !reflect.DeepEqual(c.expected, c.actual) {
+			t.Errorf("%s: expected position %v, got %v", c.desc, c.expected, c.actual)
+		}
+	}
+}
+
+func TestGroupsConfigLoadErrorPositions(t *testing.T) {
+	cases := []struct {
+		desc         string
+		files        map[string]string
+		restrictions []Restriction
+		// expectedErrs are regular expressions of errors in the returned
+		// error, where {dir} is replaced by the groups directory.
+		expectedErrs []string
+	}{
+		{
+			desc: "duplicate members and delivery for an unknown address",
+			files: map[string]string{
+				"groups.yaml": `groups:
+  - email-id: group1@example.com
+    members:
+      - member@example.com
+      - Member@example.com
+    delivery:
+      other@example.com: DIGEST
+`,
+			},
+			expectedErrs: []string{
+				`{dir}/groups.yaml:5:9: group "group1@example.com" lists the same person`,
+				`{dir}/groups.yaml:7:7: group "group1@example.com" has delivery for "other@example.com", which is not`,
+			},
+		},
+		{
+			desc: "invalid delivery",
+			files: map[string]string{
+				"groups.yaml": `groups:
+  - email-id: group1@example.com
+    members:
+      - member@example.com
+    delivery:
+      member@example.com: WEEKLY
+`,
+			},
+			expectedErrs: []string{
+				`{dir}/groups.yaml:6:27: groups\[0\]\.delivery\.member@example\.com must be one of .*, not "WEEKLY"`,
+			},
+		},
+		{
+			desc: "group not allowed by restrictions",
+			files: map[string]string{
+				"sig-foo/groups.yaml": `groups:
+  - email-id: sig-foo@example.com
+  - email-id: sig-bar@example.com
+`,
+			},
+			restrictions: []Restriction{
+				{Path: "sig-foo/groups.yaml", AllowedGroups: []string{"^sig-foo@"}},
+			},
+			expectedErrs: []string{
+				`{dir}/sig-foo/groups.yaml:3:5: cannot define group "sig-bar@example.com" in "sig-foo/groups.yaml"`,
+			},
+		},
+		{
+			desc: "duplicate group across files",
+			files: map[string]string{
+				"a/groups.yaml": `groups:
+  - email-id: group1@example.com
+`,
+				"b/groups.yaml": `groups:
+  - email-id: group2@example.com
+  - email-id: group1@example.com
+`,
+			},
+			expectedErrs: []string{
+				`{dir}/b/groups.yaml:3:5: cannot overwrite group definitions \(duplicate group name group1@example.com, first defined at {dir}/a/groups.yaml:2:5\)`,
+			},
+		},
+		{
+			desc: "group without email-id",
+			files: map[string]string{
+				"groups.yaml": `groups:
+  - name: group1
+`,
+			},
+			expectedErrs: []string{
+				`{dir}/groups.yaml:2:5: groups\[0\] is missing required field email-id`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range c.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			restrictions := RestrictionsConfig{}
+			for _, r := range c.restrictions {
+				for _, g := range r.AllowedGroups {
+					r.AllowedGroupsRe = append(r.AllowedGroupsRe, regexp.MustCompile(g))
+				}
+				restrictions.Restrictions = append(restrictions.Restrictions, r)
+			}
+
+			var gc GroupsConfig
+			err := gc.Load(dir, &restrictions)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, expected := range c.expectedErrs {
+				re := regexp.MustCompile(strings.ReplaceAll(expected, "{dir}", regexp.QuoteMeta(dir)))
+				if !re.MatchString(err.Error()) {
+					t.Errorf("expected error matching %q, got: %v", re, err)
+				}
+			}
+			if n := len(positionErrors(err)); n != len(c.expectedErrs) {
+				t.Errorf("expected %d errors with a position, got %d: %v", len(c.expectedErrs), n, err)
+			}
+		})
+	}
+}
+
+func TestGithubAnnotations(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := GoogleGroup{
+		EmailId: "group1@example.com",
+		Members: []string{"a@example.com", "a@example.com"},
+		source: groupSource{
+			pos:     Position{Path: filepath.Join(wd, "sig-foo", "groups.yaml"), Line: 2, Column: 5},
+			members: map[string][]Position{MemberRole: {{Line: 4, Column: 9}, {Line: 5, Column: 9}}},
+		},
+	}
+	err = (&Tenant{Name: "k8s", Domain: "kubernetes.io"}).CheckGroupDomains([]GoogleGroup{g})
+
+	expected := []string{
+		"::error file=sig-foo/groups.yaml,line=2,col=5::group group1@example.com does not belong to domain kubernetes.io of tenant k8s",
+		`::error file=sig-foo/groups.yaml,line=5,col=9::group "group1@example.com" lists "a@example.com" more than once (as MEMBER and MEMBER)`,
+	}
+	err = fmt.Errorf("tenant k8s: %w", utilerrors.NewAggregate(append([]error{err}, checkDuplicateMembers(g)...)))
+	actual := githubAnnotations(err)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected annotations:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -25,14 +25,10 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
-	secretmanager "cloud.google.com/go/secretmanager/apiv1"
-	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	"github.com/bmatcuk/doublestar"
 	"golang.org/x/net/context"
-	"golang.org/x/oauth2/google"
-	admin "google.golang.org/api/admin/directory/v1"
-	"google.golang.org/api/groupssettings/v1"
 	"google.golang.org/api/option"
 	"gopkg.in/yaml.v3"
 
@@ -41,40 +37,138 @@ import (
 )
 
 type Config struct {
+	// Tenant configures the single Google Workspace tenant whose groups are
+	// managed, for configs that don't declare Tenants.
+	Tenant `yaml:",inline"`
+
+	// Tenants configures multiple Google Workspace tenants, each with its own
+	// credentials, groups.yaml files and restrictions. They are reconciled
+	// one after another in a single run. The top-level tenant fields must
+	// not be set if Tenants is.
+	//
+	// After Load, Tenants always contains at least one tenant.
+	Tenants []Tenant `yaml:"tenants,omitempty"`
+
+	// If false, don't make any mutating API calls
+	ConfirmChanges bool
+}
+
+// Tenant configures the management of the groups of a single Google
+// Workspace customer or domain.
+type Tenant struct {
+	// Name identifies the tenant in logs and reports. If not specified, it
+	// defaults to Domain, or "default" for a config without Tenants.
+	Name string `yaml:"name,omitempty"`
+
 	// the email id for the bot/service account
 	BotID string `yaml:"bot-id"`
 
-	// the gcloud secret containing a service account key to authenticate with
+	// CredentialSource selects how credentials for the Google APIs are
+	// obtained. One of "secret-manager" (default), "key-file" or
+	// "impersonation".
+	CredentialSource string `yaml:"credential-source,omitempty"`
+
+	// the gcloud secret containing a service account key to authenticate with,
+	// used by the "secret-manager" credential source
 	SecretVersion string `yaml:"secret-version,omitempty"`
 
+	// KeyFile is the path to a service account key file, used by the
+	// "key-file" credential source. Relative paths are resolved against
+	// the directory containing the config.yaml file.
+	KeyFile string `yaml:"key-file,omitempty"`
+
+	// ServiceAccount is the email of a service account with domain-wide
+	// delegation that Application Default Credentials impersonate, used by
+	// the "impersonation" credential source.
+	ServiceAccount string `yaml:"service-account,omitempty"`
+
 	// GroupsPath is the path to the directory with
 	// groups.yaml files containing groups/members information.
-	// It must be an absolute path. If not specified,
-	// it defaults to the directory containing the config.yaml file.
+	// Relative paths are resolved against the directory containing the
+	// config.yaml file, which is also the default.
 	GroupsPath string `yaml:"groups-path,omitempty"`
 
-	// RestrictionsPath is the absolute path to the configuration file
+	// Customer is the ID of the Google Workspace customer whose groups are
+	// listed. If not specified, it defaults to the customer of the bot-id.
+	Customer string `yaml:"customer,omitempty"`
+
+	// Domain restricts the listed groups, and thus the groups that may be
+	// deleted, to those of a single domain of the customer.
+	Domain string `yaml:"domain,omitempty"`
+
+	// GroupsQuery is an optional Directory API search query further
+	// restricting the listed groups, e.g. "email:k8s-infra*".
+	GroupsQuery string `yaml:"groups-query,omitempty"`
+
+	// RestrictionsPath is the path to the configuration file
 	// containing restrictions for which groups can be defined in sub-directories.
-	// If not specified, it defaults to "restrictions.yaml" in the groups-path directory.
+	// Relative paths are resolved against the directory containing the
+	// config.yaml file. If not specified, it defaults to "restrictions.yaml"
+	// in that directory.
 	RestrictionsPath string `yaml:"restrictions-path,omitempty"`
 
-	// If false, don't make any mutating API calls
-	ConfirmChanges bool
+	// DeletionGracePeriod is how long a group removed from the groups.yaml
+	// files is kept locked, pending deletion, before it is deleted, e.g.
+	// "720h". If not specified, it defaults to 30 days. If 0, such groups
+	// are deleted right away.
+	DeletionGracePeriod *time.Duration `yaml:"deletion-grace-period,omitempty"`
+
+	// SnapshotPath is where a snapshot of a group, with its settings and
+	// members, is saved before it is deleted: a local directory, resolved
+	// against the directory containing the config.yaml file if relative,
+	// or a Cloud Storage location of the form gs://<bucket>[/<prefix>].
+	// Groups are never deleted if it is not specified.
+	SnapshotPath string `yaml:"snapshot-path,omitempty"`
+
+	// CloudIdentity enables reconciling the labels of groups with the Cloud
+	// Identity Groups API, which requests the cloud-identity.groups scope in
+	// addition to the Admin Directory and Groups Settings ones. Groups may
+	// only set labels if it is enabled.
+	CloudIdentity bool `yaml:"cloud-identity,omitempty"`
 }
 
 type GroupsConfig struct {
 	// This file has the list of groups in kubernetes.io gsuite org that we use
 	// for granting permissions to various community resources.
 	Groups []GoogleGroup `yaml:"groups,omitempty" json:"groups,omitempty"`
+
+	// Teams is how some files list their groups, which are not reconciled.
+	// It is only accepted so that these files still validate until their
+	// groups are moved to Groups.
+	// +optional
+	Teams []GoogleGroup `yaml:"teams,omitempty" json:"teams,omitempty"`
 }
 
 type GoogleGroup struct {
-	EmailId     string `yaml:"email-id" json:"email-id"`
+	EmailId string `yaml:"email-id" json:"email-id"`
+
+	// PreviousEmailIds are the former email ids of the group. A group that
+	// still has one of them is renamed to EmailId, keeping the previous
+	// address as an alias, rather than deleted and created afresh.
+	// +optional
+	PreviousEmailIds []string `yaml:"previous-email-ids,omitempty" json:"previous-email-ids,omitempty"`
+
+	// Name and Description are only reconciled if the group sets them,
+	// which clears them if set to "". Otherwise they are left as they are.
 	Name        string `yaml:"name" json:"name"`
 	Description string `yaml:"description" json:"description"`
 
+	// Aliases are additional addresses of the group. If the group sets
+	// them, aliases that aren't listed are removed, so "aliases: []"
+	// removes them all. Otherwise they are left as they are.
+	// +optional
+	Aliases []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+
 	Settings map[string]string `yaml:"settings,omitempty" json:"settings,omitempty"`
 
+	// Labels are the Cloud Identity labels of the group, e.g.
+	// "cloudidentity.googleapis.com/groups.security" set to "" makes it a
+	// security group, as GKE Group-based RBAC requires. Listed labels are
+	// added or updated, others are left as they are. They require the
+	// tenant to enable cloud-identity.
+	// +optional
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
 	// +optional
 	Owners []string `yaml:"owners,omitempty" json:"owners,omitempty"`
 
@@ -83,12 +177,111 @@ type GoogleGroup struct {
 
 	// +optional
 	Members []string `yaml:"members,omitempty" json:"members,omitempty"`
+
+	// Delivery maps the email of an owner, manager or member to how they
+	// receive messages: ALL_MAIL, DIGEST, DAILY, NONE or DISABLED.
+	// Addresses that aren't listed keep their current delivery setting.
+	// +optional
+	Delivery map[string]string `yaml:"delivery,omitempty" json:"delivery,omitempty"`
+
+	// Schedule maps the email of an owner, manager or member to when it is
+	// one. Before From and from Until, the address is reconciled as if it
+	// wasn't listed, so that role changes take effect on the first run after
+	// these times. Addresses are removed at Until even if ReconcileMembers
+	// isn't set.
+	// +optional
+	Schedule map[string]MemberSchedule `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
+	// source records where the group was defined, see UnmarshalYAML.
+	source groupSource
+}
+
+// MemberSchedule is when an address is an owner, manager or member of a
+// group.
+type MemberSchedule struct {
+	// From is when the address is added, e.g. 2026-06-01T00:00:00Z. If not
+	// specified, it is added right away.
+	// +optional
+	From *time.Time `yaml:"from,omitempty" json:"from,omitempty"`
+	// Until is when the address is removed. If not specified, it is kept.
+	// +optional
+	Until *time.Time `yaml:"until,omitempty" json:"until,omitempty"`
+	// Roles maps OWNER, MANAGER or MEMBER to when the address has that
+	// role, instead of From and Until, so that an address listed in two
+	// roles can change role on a date, e.g. a MEMBER until the time it is a
+	// MANAGER from. The times of its roles must not overlap.
+	// +optional
+	Roles map[string]RoleSchedule `yaml:"roles,omitempty" json:"roles,omitempty"`
+}
+
+// RoleSchedule is when an address has a given role in a group.
+type RoleSchedule struct {
+	// +optional
+	From *time.Time `yaml:"from,omitempty" json:"from,omitempty"`
+	// +optional
+	Until *time.Time `yaml:"until,omitempty" json:"until,omitempty"`
+}
+
+// HasEmail reports whether email is the email id or one of the previous
+// email ids of the group.
+func (g GoogleGroup) HasEmail(email string) bool {
+	if EmailAddressEquals(g.EmailId, email) {
+		return true
+	}
+	for _, id := range g.PreviousEmailIds {
+		if EmailAddressEquals(id, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryFor returns the delivery setting configured for the given address,
+// or "" if it has none.
+func (g GoogleGroup) DeliveryFor(email string) string {
+	for e, delivery := range g.Delivery {
+		if EmailAddressEquals(e, email) {
+			return delivery
+		}
+	}
+	return ""
 }
 
 // RestrictionsConfig contains the list of restrictions for
 // which groups can be defined in sub-directories.
 type RestrictionsConfig struct {
+	// MinOwnersAndManagers is the minimum number of distinct owners and
+	// managers of each group who are people, i.e. neither groups defined in
+	// groups.yaml files nor service accounts. Restrictions may override it
+	// for their Path. Defaults to 0, as groups without owners or managers
+	// are only administered by the bot.
+	MinOwnersAndManagers int `yaml:"minOwnersAndManagers,omitempty" json:"minOwnersAndManagers,omitempty"`
+
 	Restrictions []Restriction `yaml:"restrictions,omitempty" json:"restrictions,omitempty"`
+
+	// Sensitive marks the groups whose changes apply only makes if they are
+	// approved, rather than on --confirm alone.
+	Sensitive Sensitive `yaml:"sensitive,omitempty" json:"sensitive,omitempty"`
+}
+
+// Sensitive selects sensitive groups, by the groups.yaml file defining them
+// or by email id.
+type Sensitive struct {
+	// Paths are globs of the groups.yaml files, relative to the groups-path,
+	// whose groups are sensitive.
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+	// Groups is the list of regular expressions for email-ids of sensitive
+	// groups. Unlike Paths, they also match existing groups that were removed
+	// from the groups.yaml files.
+	//
+	// Compiles to GroupsRe during config load.
+	Groups []string `yaml:"groups,omitempty" json:"groups,omitempty"`
+	// AllowedSigners is the path, relative to the restrictions config file,
+	// of the SSH allowed signers file of who may approve changes to sensitive
+	// groups. Approval files are refused without it.
+	AllowedSigners string `yaml:"allowedSigners,omitempty" json:"allowedSigners,omitempty"`
+
+	GroupsRe []*regexp.Regexp `yaml:"-" json:"-"`
 }
 
 type Restriction struct {
@@ -100,15 +293,11 @@ type Restriction struct {
 	// Compiles to AllowedGroupsRe during config load.
 	AllowedGroups []string `yaml:"allowedGroups" json:"allowedGroups"`
 
-	AllowedGroupsRe []*regexp.Regexp
-}
+	AllowedGroupsRe []*regexp.Regexp `yaml:"-" json:"-"`
 
-func Usage() {
-	fmt.Fprintf(os.Stderr, `
-Usage: %s [-config <config-yaml-file>] [--confirm]
-Command line flags override config values.
-`, os.Args[0])
-	flag.PrintDefaults()
+	// MinOwnersAndManagers overrides RestrictionsConfig.MinOwnersAndManagers
+	// for the groups defined for the Path.
+	MinOwnersAndManagers *int `yaml:"minOwnersAndManagers,omitempty" json:"minOwnersAndManagers,omitempty"`
 }
 
 var (
@@ -119,90 +308,251 @@ var (
 	verbose = flag.Bool("v", false, "log extra information")
 
 	defaultConfigFile       = "config.yaml"
+	defaultTenantName       = "default"
 	defaultRestrictionsFile = "restrictions.yaml"
 	emptyRegexp             = regexp.MustCompile("")
 	defaultRestriction      = Restriction{Path: "*", AllowedGroupsRe: []*regexp.Regexp{emptyRegexp}}
 	defaultNumWorkers       = 5
 )
 
+// groupsPackage is the import path of this package.
+const groupsPackage = "k8s.io/k8s.io/groups"
+
 func main() {
-	configFilePath := flag.String("config", defaultConfigFile, "the config file in yaml format")
-	confirmChanges := flag.Bool("confirm", false, "false by default means that we do not push anything to google groups")
-	printConfig := flag.Bool("print", false, "print the existing group information")
-	numWorkers := flag.Int("workers", defaultNumWorkers, "number of concurrent workers to use")
+	opts := options{}
+	opts.addFlags(flag.CommandLine, true)
+	confirmChanges := flag.Bool("confirm", false, "deprecated, use the apply command")
+	printConfig := flag.Bool("print", false, "deprecated, use the print command")
+	verifyMembers := flag.Bool("verify-members", false, "deprecated, use the verify-members command")
 
 	flag.Usage = Usage
 	flag.Parse()
 
-	if *printConfig {
-		log.Printf("print: %v -- disabling confirm, will print existing group information", *confirmChanges)
-		*confirmChanges = false
+	name, args := flag.Arg(0), flag.Args()
+	if len(args) > 0 {
+		args = args[1:]
+	} else {
+		var err error
+		name, err = legacyCommand(*confirmChanges, *printConfig, *verifyMembers)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("no command given, running %q; running without a command is deprecated", name)
 	}
-	if !*confirmChanges {
-		log.Printf("confirm: %v -- dry-run mode, changes will not be pushed", *confirmChanges)
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		log.Fatalf("unknown command %q, run %s -help for the list of commands", name, os.Args[0])
 	}
-	if *numWorkers < 1 {
-		*numWorkers = 1
+	if err := cmd.run(&opts, args); err != nil {
+		log.Fatal(err)
 	}
-	log.Printf("workers: %v", *numWorkers)
+}
 
-	err := config.Load(*configFilePath, *confirmChanges)
-	if err != nil {
-		log.Fatal(err)
+// tenantReport summarizes the outcome of reconciling the groups of a tenant.
+type tenantReport struct {
+	tenant string
+	groups int
+	err    error
+}
+
+func (r tenantReport) String() string {
+	if r.err == nil {
+		return fmt.Sprintf("tenant %s: reconciled %d groups", r.tenant, r.groups)
+	}
+	n := 1
+	if agg, ok := r.err.(utilerrors.Aggregate); ok {
+		n = len(agg.Errors())
 	}
+	return fmt.Sprintf("tenant %s: %d errors reconciling %d groups", r.tenant, n, r.groups)
+}
 
-	log.Printf("config: BotID:            %v", config.BotID)
-	log.Printf("config: SecretVersion:    %v", config.SecretVersion)
-	log.Printf("config: GroupsPath:       %v", config.GroupsPath)
-	log.Printf("config: RestrictionsPath: %v", config.RestrictionsPath)
-	log.Printf("config: ConfirmChanges:   %v", config.ConfirmChanges)
+// runMode selects what is done with the groups of each tenant.
+type runMode int
+
+const (
+	// reconcileMode reconciles the groups.
+	reconcileMode runMode = iota
+	// printMode prints the existing groups.
+	printMode
+	// verifyMembersMode reports stale members of the groups.
+	verifyMembersMode
+	// validateMode only loads and checks the groups, without credentials.
+	validateMode
+	// diffMode prints the differences between the existing and the
+	// configured groups.
+	diffMode
+	// purgeMode deletes groups pending deletion.
+	purgeMode
+	// exportGraphMode collects the existing groups and their members for
+	// the export-graph command.
+	exportGraphMode
+)
 
-	err = restrictionsConfig.Load(config.RestrictionsPath)
-	if err != nil {
-		log.Fatal(err)
+// reconcileTenant loads the groups of tenant t and reconciles them, prints
+// the existing groups of the tenant, verifies their members, prints how
+// they differ from the existing groups, purges groups pending deletion or
+// collects the existing groups to export their graph, depending on mode. In
+// validateMode, it stops after loading the groups, so no credentials are
+// needed.
+//
+// The services read the tenant being reconciled from the package level config,
+// groupsConfig and restrictionsConfig, so tenants must not be reconciled
+// concurrently.
+func reconcileTenant(ctx context.Context, t Tenant, o *options, mode runMode) tenantReport {
+	report := tenantReport{tenant: t.Name}
+
+	report.err = loadTenant(t)
+	report.groups = len(groupsConfig.Groups)
+	if report.err != nil || mode == validateMode {
+		return report
 	}
 
-	err = groupsConfig.Load(config.GroupsPath, &restrictionsConfig)
+	credentials, err := NewCredentialProvider(&t)
 	if err != nil {
-		log.Fatal(err)
+		report.err = err
+		return report
 	}
+	log.Printf("credentials: %v", credentials)
 
-	serviceAccountKey, err := accessSecretVersion(config.SecretVersion)
+	clientOption, err := credentials.ClientOption(ctx)
 	if err != nil {
-		log.Fatalf("Unable to access secret-version %s, %v", config.SecretVersion, err)
+		report.err = err
+		return report
 	}
 
-	credential, err := google.JWTConfigFromJSON(serviceAccountKey, admin.AdminDirectoryUserReadonlyScope,
-		admin.AdminDirectoryGroupScope,
-		admin.AdminDirectoryGroupMemberScope,
-		groupssettings.AppsGroupsSettingsScope)
+	r, err := NewReconciler(ctx, o.numWorkers, clientOption)
 	if err != nil {
-		log.Fatalf("Unable to authenticate using key in secret-version %s, %v", config.SecretVersion, err)
+		report.err = err
+		return report
 	}
-	credential.Subject = config.BotID
-
-	ctx := context.Background()
-	client := credential.Client(ctx)
-	clientOption := option.WithHTTPClient(client)
-
-	r, err := NewReconciler(ctx, clientOption, *numWorkers)
+	r.snapshots, err = newSnapshotStore(ctx, t.SnapshotPath)
 	if err != nil {
-		log.Fatal(err)
+		report.err = err
+		return report
+	}
+	if t.CloudIdentity {
+		r.labelService, err = NewLabelService(ctx, clientOption)
+		if err != nil {
+			report.err = err
+			return report
+		}
+	}
+
+	switch mode {
+	case printMode:
+		if len(config.Tenants) > 1 {
+			fmt.Printf("# tenant: %s\n", t.Name)
+		}
+		report.err = r.printGroupMembersAndSettings()
+		return report
+	case verifyMembersMode:
+		log.Println(" =================== Stale members =====================")
+		report.err = r.VerifyMembers(os.Stdout, groupsConfig.Groups)
+		return report
+	case diffMode:
+		report.err = r.DiffGroups(os.Stdout, groupsConfig.Groups)
+		return report
+	case purgeMode:
+		report.err = r.PurgeGroups(os.Stdout, t.ownEmails(o.purgeGroups), o.purgeAll, o.approveSensitive, o.approval)
+		return report
+	case exportGraphMode:
+		report.err = r.addLiveGroups(&o.graph)
+		return report
 	}
 
-	if *printConfig {
-		err = r.printGroupMembersAndSettings()
+	groups := groupsConfig.Groups
+	if o.selector.isPartial() {
+		groups, err = o.selector.selectGroups(groups, config.GroupsPath)
 		if err != nil {
-			log.Fatal(err)
+			report.err = err
+			return report
+		}
+		report.groups = len(groups)
+		log.Printf("selected %d groups of %d", len(groups), len(groupsConfig.Groups))
+		if !o.deleteGroups {
+			log.Printf("not deleting groups that aren't in the config as only some groups are selected, use -delete to delete them")
+			r.skipDeletions = true
+		}
+	}
+	if o.changedSince != "" {
+		groups, err = groupsChangedSince(o.changedSince, groups)
+		if err != nil {
+			report.err = err
+			return report
+		}
+		report.groups = len(groups)
+	}
+
+	if !restrictionsConfig.Sensitive.isEmpty() {
+		log.Println(" ================== Sensitive changes ==================")
+		if err := r.holdSensitiveChanges(os.Stdout, groups, o.approveSensitive, o.approval); err != nil {
+			report.err = err
+			return report
 		}
-		return
 	}
 
 	log.Println(" ======================= Updates =======================")
-	err = r.ReconcileGroups(groupsConfig.Groups)
+	report.err = r.ReconcileGroups(groups)
+	return report
+}
+
+// groupsChangedSince returns the groups that changed since the git revision
+// rev. Groups removed since rev are logged; they are deleted as they aren't
+// in groupsConfig. It returns an error if the groups at rev can't be loaded,
+// rather than silently reconciling every group.
+func groupsChangedSince(rev string, groups []GoogleGroup) ([]GoogleGroup, error) {
+	previous, err := groupsAtRevision(config.GroupsPath, rev)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("unable to load groups at %s, run without -changed-since to reconcile all groups: %w", rev, err)
+	}
+	changed, removed := changedGroups(previous, groups)
+	log.Printf("changed-since: %s -- reconciling %d changed groups of %d", rev, len(changed), len(groups))
+	for _, g := range changed {
+		log.Printf("changed-since: %s changed", g.EmailId)
+	}
+	for _, email := range removed {
+		log.Printf("changed-since: %s removed", email)
+	}
+	return changed, nil
+}
+
+// loadTenant sets t as the tenant being reconciled in config and loads its
+// restrictions and groups into restrictionsConfig and groupsConfig. It
+// doesn't need any credentials.
+func loadTenant(t Tenant) error {
+	config.Tenant = t
+	groupsConfig = GroupsConfig{}
+	restrictionsConfig = RestrictionsConfig{}
+
+	log.Printf(" ======================= Tenant %s =======================", t.Name)
+	log.Printf("config: BotID:            %v", config.BotID)
+	log.Printf("config: CredentialSource: %v", config.CredentialSource)
+	log.Printf("config: SecretVersion:    %v", config.SecretVersion)
+	log.Printf("config: KeyFile:          %v", config.KeyFile)
+	log.Printf("config: ServiceAccount:   %v", config.ServiceAccount)
+	log.Printf("config: GroupsPath:       %v", config.GroupsPath)
+	log.Printf("config: RestrictionsPath: %v", config.RestrictionsPath)
+	log.Printf("config: Customer:         %v", config.Customer)
+	log.Printf("config: Domain:           %v", config.Domain)
+	log.Printf("config: GroupsQuery:      %v", config.GroupsQuery)
+	log.Printf("config: DeletionGracePeriod: %v", config.GetDeletionGracePeriod())
+	log.Printf("config: SnapshotPath:     %v", config.SnapshotPath)
+	log.Printf("config: CloudIdentity:    %v", config.CloudIdentity)
+
+	if err := restrictionsConfig.Load(config.RestrictionsPath); err != nil {
+		return err
 	}
+	if err := groupsConfig.Load(config.GroupsPath, &restrictionsConfig); err != nil {
+		return err
+	}
+	if err := t.CheckGroupDomains(groupsConfig.Groups); err != nil {
+		return err
+	}
+	if err := t.CheckGroupLabels(groupsConfig.Groups); err != nil {
+		return err
+	}
+	return t.CheckBotID(groupsConfig.Groups)
 }
 
 // Reconciler syncs the actual state of the world with the configuration.
@@ -212,15 +562,27 @@ type Reconciler struct {
 	adminService AdminService
 	groupService GroupService
 	numWorkers   int
+	// skipDeletions keeps ReconcileGroups from deleting the groups that
+	// aren't in the config.
+	skipDeletions bool
+	// snapshots saves the snapshots of groups before they are deleted, or
+	// is nil if groups may not be deleted.
+	snapshots snapshotStore
+	// labelService reconciles the labels of groups, or is nil if the tenant
+	// doesn't enable cloud-identity.
+	labelService LabelService
+	// held are the emails of the sensitive groups whose changes aren't
+	// approved, which ReconcileGroups leaves as they are.
+	held []string
 }
 
-func NewReconciler(ctx context.Context, clientOption option.ClientOption, numWorkers int) (*Reconciler, error) {
-	as, err := NewAdminService(ctx, clientOption)
+func NewReconciler(ctx context.Context, numWorkers int, clientOptions ...option.ClientOption) (*Reconciler, error) {
+	as, err := NewAdminService(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	gs, err := NewGroupService(ctx, clientOption)
+	gs, err := NewGroupService(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -231,6 +593,16 @@ func NewReconciler(ctx context.Context, clientOption option.ClientOption, numWor
 func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 	// aggregate the errors that occurred and return them together in the end.
 	var errs []error
+	if len(r.held) > 0 {
+		errs = append(errs, fmt.Errorf("not changing sensitive groups %s as their changes aren't approved, apply them with -approve-sensitive or an -approval-file listing them", strings.Join(r.held, ", ")))
+		var approved []GoogleGroup
+		for _, g := range groups {
+			if !r.isHeld(g.EmailId) {
+				approved = append(approved, g)
+			}
+		}
+		groups = approved
+	}
 	groupChan := make(chan GoogleGroup, len(groups))
 	for _, g := range groups {
 		groupChan <- g
@@ -252,6 +624,8 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 			defer wg.Done()
 			var errs []error
 			for g := range groups {
+				ended := g.endedAt(now())
+				g = g.asOf(now())
 				if g.EmailId == "" {
 					errs = append(errs, fmt.Errorf("group has no email-id: %#v", g))
 				}
@@ -266,6 +640,13 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 					errs = append(errs, err)
 				}
 
+				if r.labelService != nil {
+					err = r.labelService.UpdateGroupLabels(g)
+					if err != nil {
+						errs = append(errs, err)
+					}
+				}
+
 				err = r.adminService.AddOrUpdateGroupMembers(g, OwnerRole, g.Owners)
 				if err != nil {
 					errs = append(errs, err)
@@ -294,6 +675,12 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 					if err != nil {
 						errs = append(errs, err)
 					}
+					if len(ended) > 0 {
+						err = r.adminService.RemoveGroupMembers(g, ended)
+						if err != nil {
+							errs = append(errs, err)
+						}
+					}
 				}
 			}
 			errsChan <- errs
@@ -307,9 +694,11 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 		errs = append(errs, workerErrs...)
 	}
 
-	err := r.adminService.DeleteGroupsIfNecessary()
-	if err != nil {
-		errs = append(errs, err)
+	if !r.skipDeletions {
+		err := r.DeleteGroupsIfNecessary()
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	return utilerrors.NewAggregate(errs)
@@ -322,11 +711,15 @@ func (r *Reconciler) printGroupMembersAndSettings() error {
 	}
 
 	var groupsConfig GroupsConfig
+	// memberTypes maps group email to the type of its members that are not
+	// users, e.g. nested groups.
+	memberTypes := map[string]map[string]string{}
 	for _, g := range g.Groups {
 		group := GoogleGroup{
 			EmailId:     g.Email,
 			Name:        g.Name,
 			Description: g.Description,
+			Aliases:     g.Aliases,
 		}
 		g2, err := r.groupService.Get(g.Email)
 		if err != nil {
@@ -359,21 +752,114 @@ func (r *Reconciler) printGroupMembersAndSettings() error {
 			case MemberRole:
 				group.Members = append(group.Members, m.Email)
 			}
+			if m.DeliverySettings != "" && m.DeliverySettings != AllMailDelivery {
+				if group.Delivery == nil {
+					group.Delivery = map[string]string{}
+				}
+				group.Delivery[m.Email] = m.DeliverySettings
+			}
+			if m.Type != "" && m.Type != UserMemberType {
+				if memberTypes[g.Email] == nil {
+					memberTypes[g.Email] = map[string]string{}
+				}
+				memberTypes[g.Email][m.Email] = m.Type
+			}
 		}
 
 		groupsConfig.Groups = append(groupsConfig.Groups, group)
 	}
 
-	cm, err := genyaml.NewCommentMap(nil, nil, "reconcile.go")
+	yamlSnippet, err := renderGroupsConfig(groupsConfig, memberTypes)
 	if err != nil {
-		return fmt.Errorf("failed to construct commentMap: %w", err)
+		return err
+	}
+	fmt.Println(yamlSnippet)
+	return nil
+}
+
+// renderGroupsConfig returns groupsConfig in the groups.yaml format, with
+// the comments of the types and the types of the members in memberTypes, see
+// annotateMemberTypes.
+func renderGroupsConfig(groupsConfig GroupsConfig, memberTypes map[string]map[string]string) (string, error) {
+	// genyaml resolves the package of the given files to find the comments
+	// of the types, which are all in this package.
+	cm, err := genyaml.NewCommentMap(func(string) (string, error) { return groupsPackage, nil }, nil, "reconcile.go")
+	if err != nil {
+		return "", fmt.Errorf("failed to construct commentMap: %w", err)
 	}
 	yamlSnippet, err := cm.GenYaml(groupsConfig)
 	if err != nil {
-		return fmt.Errorf("unable to generate yaml for groups : %w", err)
+		return "", fmt.Errorf("unable to generate yaml for groups : %w", err)
+	}
+	yamlSnippet, err = annotateMemberTypes(yamlSnippet, memberTypes)
+	if err != nil {
+		return "", fmt.Errorf("unable to annotate member types : %w", err)
 	}
+	return yamlSnippet, nil
+}
 
-	fmt.Println(yamlSnippet)
+// annotateMemberTypes adds a "type: <TYPE>" line comment to the owners,
+// managers and members of the groups in yamlSnippet that have a type in
+// memberTypes, keyed by group email then member email, so that e.g. nested
+// groups can be told apart from users.
+func annotateMemberTypes(yamlSnippet string, memberTypes map[string]map[string]string) (string, error) {
+	if len(memberTypes) == 0 {
+		return yamlSnippet, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlSnippet), &doc); err != nil {
+		return "", err
+	}
+	if len(doc.Content) == 0 {
+		return yamlSnippet, nil
+	}
+
+	groups := mappingValue(doc.Content[0], "groups")
+	if groups == nil {
+		return yamlSnippet, nil
+	}
+	for _, group := range groups.Content {
+		emailId := mappingValue(group, "email-id")
+		if emailId == nil {
+			continue
+		}
+		types := memberTypes[emailId.Value]
+		for _, key := range []string{"owners", "managers", "members"} {
+			list := mappingValue(group, key)
+			if list == nil {
+				continue
+			}
+			for _, member := range list.Content {
+				if t, ok := types[member.Value]; ok {
+					member.LineComment = "type: " + t
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// mappingValue returns the value of key in the mapping node, or nil if node
+// isn't a mapping or doesn't contain key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
 	return nil
 }
 
@@ -387,24 +873,123 @@ func (c *Config) Load(configFilePath string, confirmChanges bool) error {
 		return fmt.Errorf("error parsing config file %s: %w", configFilePath, err)
 	}
 
-	if !filepath.IsAbs(c.GroupsPath) {
-		c.GroupsPath = filepath.Clean(filepath.Join(filepath.Dir(configFilePath), c.GroupsPath))
+	if len(c.Tenants) == 0 {
+		if c.Name == "" {
+			c.Name = c.Domain
+		}
+		if c.Name == "" {
+			c.Name = defaultTenantName
+		}
+		c.Tenants = []Tenant{c.Tenant}
+	} else if c.Tenant != (Tenant{}) {
+		return fmt.Errorf("error parsing config file %s: tenant fields must be set per tenant if tenants are declared", configFilePath)
+	}
+
+	names := map[string]struct{}{}
+	for i := range c.Tenants {
+		t := &c.Tenants[i]
+		if t.Name == "" {
+			t.Name = t.Domain
+		}
+		if t.Name == "" {
+			return fmt.Errorf("error parsing config file %s: tenant %d must have a name or domain", configFilePath, i)
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("error parsing config file %s: duplicate tenant name %s", configFilePath, t.Name)
+		}
+		names[t.Name] = struct{}{}
+
+		if t.DeletionGracePeriod != nil && *t.DeletionGracePeriod < 0 {
+			return fmt.Errorf("error parsing config file %s: tenant %s: deletion-grace-period must not be negative", configFilePath, t.Name)
+		}
+
+		if err := t.resolvePaths(filepath.Dir(configFilePath)); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
 	}
-	c.GroupsPath, err = filepath.Abs(c.GroupsPath)
+	if len(c.Tenants) == 1 {
+		c.Tenant = c.Tenants[0]
+	}
+
+	c.ConfirmChanges = confirmChanges
+	return err
+}
+
+// resolvePaths resolves the paths of the tenant relative to configDir and
+// converts them to absolute paths.
+func (t *Tenant) resolvePaths(configDir string) error {
+	var err error
+	if !filepath.IsAbs(t.GroupsPath) {
+		t.GroupsPath = filepath.Clean(filepath.Join(configDir, t.GroupsPath))
+	}
+	t.GroupsPath, err = filepath.Abs(t.GroupsPath)
 	if err != nil {
-		return fmt.Errorf("error converting groups-path %v to absolute path: %w", c.GroupsPath, err)
+		return fmt.Errorf("error converting groups-path %v to absolute path: %w", t.GroupsPath, err)
 	}
 
-	if c.RestrictionsPath == "" {
-		c.RestrictionsPath = filepath.Join(filepath.Dir(configFilePath), defaultRestrictionsFile)
+	if t.RestrictionsPath == "" {
+		t.RestrictionsPath = defaultRestrictionsFile
+	}
+	if !filepath.IsAbs(t.RestrictionsPath) {
+		t.RestrictionsPath = filepath.Join(configDir, t.RestrictionsPath)
 	}
-	c.RestrictionsPath, err = filepath.Abs(c.RestrictionsPath)
+	t.RestrictionsPath, err = filepath.Abs(t.RestrictionsPath)
 	if err != nil {
-		return fmt.Errorf("error converting retrictions-path %v to absolute path: %w", c.RestrictionsPath, err)
+		return fmt.Errorf("error converting retrictions-path %v to absolute path: %w", t.RestrictionsPath, err)
 	}
 
-	c.ConfirmChanges = confirmChanges
-	return err
+	if t.KeyFile != "" && !filepath.IsAbs(t.KeyFile) {
+		t.KeyFile = filepath.Clean(filepath.Join(configDir, t.KeyFile))
+	}
+	if t.SnapshotPath != "" && !strings.HasPrefix(t.SnapshotPath, gcsScheme) && !filepath.IsAbs(t.SnapshotPath) {
+		t.SnapshotPath = filepath.Clean(filepath.Join(configDir, t.SnapshotPath))
+	}
+	return nil
+}
+
+// GetDeletionGracePeriod returns how long a group removed from the
+// groups.yaml files is kept locked before it is deleted.
+func (t *Tenant) GetDeletionGracePeriod() time.Duration {
+	if t.DeletionGracePeriod == nil {
+		return defaultDeletionGracePeriod
+	}
+	return *t.DeletionGracePeriod
+}
+
+// ownEmails returns the emails that belong to the domain of the tenant, or
+// all of them if it has none.
+func (t *Tenant) ownEmails(emails []string) []string {
+	if t.Domain == "" {
+		return emails
+	}
+	var own []string
+	for _, email := range emails {
+		if strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(t.Domain)) {
+			own = append(own, email)
+		}
+	}
+	return own
+}
+
+// CheckGroupDomains returns an error for each group that doesn't belong to
+// the domain of the tenant, if it has one. This keeps a tenant from
+// managing, or being confused by, groups of another tenant.
+func (t *Tenant) CheckGroupDomains(groups []GoogleGroup) error {
+	if t.Domain == "" {
+		return nil
+	}
+	var errs []error
+	for _, g := range groups {
+		if !strings.HasSuffix(strings.ToLower(g.EmailId), "@"+strings.ToLower(t.Domain)) {
+			errs = append(errs, errorAt(g.Position(), "group %s does not belong to domain %s of tenant %s", g.EmailId, t.Domain, t.Name))
+		}
+		for _, id := range g.PreviousEmailIds {
+			if !strings.HasSuffix(strings.ToLower(id), "@"+strings.ToLower(t.Domain)) {
+				errs = append(errs, errorAt(g.Position(), "previous email id %s of group %s does not belong to domain %s of tenant %s", id, g.EmailId, t.Domain, t.Name))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
 }
 
 // Load populates the RestrictionsConfig with data parsed from path and returns
@@ -415,6 +1000,9 @@ func (rc *RestrictionsConfig) Load(path string) error {
 	if err != nil {
 		return fmt.Errorf("error reading restrictions config file %s: %w", path, err)
 	}
+	if errs := RestrictionsSchema().Validate(path, content); len(errs) > 0 {
+		return fmt.Errorf("invalid restrictions config file %s: %w", path, utilerrors.NewAggregate(errs))
+	}
 	if err = yaml.Unmarshal(content, &rc); err != nil {
 		return fmt.Errorf("error parsing restrictions config file %s: %w", path, err)
 	}
@@ -432,6 +1020,18 @@ func (rc *RestrictionsConfig) Load(path string) error {
 		ret = append(ret, r)
 	}
 	rc.Restrictions = ret
+
+	rc.Sensitive.GroupsRe = make([]*regexp.Regexp, 0, len(rc.Sensitive.Groups))
+	for _, g := range rc.Sensitive.Groups {
+		re, err := regexp.Compile(g)
+		if err != nil {
+			return fmt.Errorf("error parsing sensitive group pattern %q: %w", g, err)
+		}
+		rc.Sensitive.GroupsRe = append(rc.Sensitive.GroupsRe, re)
+	}
+	if rc.Sensitive.AllowedSigners != "" && !filepath.IsAbs(rc.Sensitive.AllowedSigners) {
+		rc.Sensitive.AllowedSigners = filepath.Join(filepath.Dir(path), rc.Sensitive.AllowedSigners)
+	}
 	return err
 }
 
@@ -439,30 +1039,28 @@ func (rc *RestrictionsConfig) Load(path string) error {
 // all directories and files. It reads the GroupsConfig from all groups.yaml
 // files and verifies that the groups in GroupsConfig satisfy the
 // restrictions in restrictionsConfig.
-// Finally, it adds all the groups in each GroupsConfig to config.Groups.
+// Finally, it adds all the groups in each GroupsConfig to config.Groups and
+// checks that each group has the owners and managers the restrictions
+// require.
 func (gc *GroupsConfig) Load(rootDir string, restrictions *RestrictionsConfig) error {
 	log.Printf("reading groups.yaml files recursively at %s", rootDir)
 
-	return filepath.Walk(rootDir, func(path string, info os.FileInfo, _ error) error {
+	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, _ error) error {
 		if filepath.Base(path) == "groups.yaml" {
 			cleanPath := strings.Trim(strings.TrimPrefix(path, rootDir), string(filepath.Separator))
 			log.Printf("groups: %s", cleanPath)
 
-			var (
-				groupsConfigAtPath GroupsConfig
-				content            []byte
-				err                error
-			)
-
-			if content, err = os.ReadFile(path); err != nil {
+			content, err := os.ReadFile(path)
+			if err != nil {
 				return fmt.Errorf("error reading groups config file %s: %w", path, err)
 			}
-			if err = yaml.Unmarshal(content, &groupsConfigAtPath); err != nil {
-				return fmt.Errorf("error parsing groups config at %s: %w", path, err)
+			groupsAtPath, err := parseGroupsFile(path, content)
+			if err != nil {
+				return err
 			}
 
 			r := restrictions.GetRestrictionForPath(path, rootDir)
-			mergedGroups, err := mergeGroups(gc.Groups, groupsConfigAtPath.Groups, r)
+			mergedGroups, err := mergeGroups(gc.Groups, groupsAtPath, r)
 			if err != nil {
 				return fmt.Errorf("couldn't merge groups: %w", err)
 			}
@@ -470,6 +1068,42 @@ func (gc *GroupsConfig) Load(rootDir string, restrictions *RestrictionsConfig) e
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	if errs := checkPreviousEmailIds(gc.Groups); len(errs) > 0 {
+		return fmt.Errorf("invalid previous email ids: %w", utilerrors.NewAggregate(errs))
+	}
+	if errs := checkOwnersAndManagers(gc.Groups, gc.Groups, restrictions, rootDir); len(errs) > 0 {
+		return fmt.Errorf("not enough owners and managers: %w", utilerrors.NewAggregate(errs))
+	}
+	return nil
+}
+
+// parseGroupsFile parses content, the groups.yaml file at path, and checks
+// the members of its groups.
+func parseGroupsFile(path string, content []byte) ([]GoogleGroup, error) {
+	if errs := GroupsSchema().Validate(path, content); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid groups config at %s: %w", path, utilerrors.NewAggregate(errs))
+	}
+	var groupsConfigAtPath GroupsConfig
+	if err := yaml.Unmarshal(content, &groupsConfigAtPath); err != nil {
+		return nil, fmt.Errorf("error parsing groups config at %s: %w", path, err)
+	}
+	for i := range groupsConfigAtPath.Groups {
+		groupsConfigAtPath.Groups[i].setPath(path)
+	}
+
+	var errs []error
+	for _, g := range groupsConfigAtPath.Groups {
+		errs = append(errs, checkDuplicateMembers(g)...)
+		errs = append(errs, checkDeliverySettings(g)...)
+		errs = append(errs, checkSchedule(g)...)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid members in groups config at %s: %w", path, utilerrors.NewAggregate(errs))
+	}
+	return groupsConfigAtPath.Groups, nil
 }
 
 // GetRestrictionForPath returns the first Restriction whose Path matches the
@@ -486,50 +1120,86 @@ func (rc *RestrictionsConfig) GetRestrictionForPath(path, rootDir string) Restri
 }
 
 func mergeGroups(a []GoogleGroup, b []GoogleGroup, r Restriction) ([]GoogleGroup, error) {
-	emails := map[string]struct{}{}
+	emails := map[string]Position{}
 	for _, v := range a {
-		emails[v.EmailId] = struct{}{}
+		emails[v.EmailId] = v.Position()
 	}
 	for _, v := range b {
 		if v.EmailId == "" {
-			return nil, fmt.Errorf("groups must have email-id")
+			return nil, errorAt(v.Position(), "groups must have email-id")
 		}
 		if !matchesRegexList(v.EmailId, r.AllowedGroupsRe) {
-			return nil, fmt.Errorf("cannot define group %q in %q", v.EmailId, r.Path)
+			return nil, errorAt(v.Position(), "cannot define group %q in %q", v.EmailId, r.Path)
 		}
-		if _, ok := emails[v.EmailId]; ok {
-			return nil, fmt.Errorf("cannot overwrite group definitions (duplicate group name %s)", v.EmailId)
+		if prev, ok := emails[v.EmailId]; ok {
+			if prev.IsValid() {
+				return nil, errorAt(v.Position(), "cannot overwrite group definitions (duplicate group name %s, first defined at %s)", v.EmailId, prev)
+			}
+			return nil, errorAt(v.Position(), "cannot overwrite group definitions (duplicate group name %s)", v.EmailId)
 		}
 	}
 	return append(a, b...), nil
 }
 
-func matchesRegexList(s string, list []*regexp.Regexp) bool {
-	for _, r := range list {
-		if r.MatchString(s) {
-			return true
+// checkPreviousEmailIds returns an error for each previous email id of
+// groups that is the email id or another previous email id of a group, as
+// the reconciler couldn't tell which group it belongs to.
+func checkPreviousEmailIds(groups []GoogleGroup) []error {
+	var errs []error
+	claimed := map[string]GoogleGroup{}
+	for _, g := range groups {
+		claimed[CanonicalEmail(g.EmailId)] = g
+	}
+	for _, g := range groups {
+		for _, id := range g.PreviousEmailIds {
+			prev, ok := claimed[CanonicalEmail(id)]
+			switch {
+			case !ok:
+				claimed[CanonicalEmail(id)] = g
+			case EmailAddressEquals(prev.EmailId, g.EmailId):
+				errs = append(errs, errorAt(g.Position(), "group %s lists %s as a previous email id more than once or as its own", g.EmailId, id))
+			case EmailAddressEquals(prev.EmailId, id) && prev.Position().IsValid():
+				errs = append(errs, errorAt(g.Position(), "previous email id %s of group %s is the email id of a group, defined at %s", id, g.EmailId, prev.Position()))
+			case EmailAddressEquals(prev.EmailId, id):
+				errs = append(errs, errorAt(g.Position(), "previous email id %s of group %s is the email id of a group", id, g.EmailId))
+			default:
+				errs = append(errs, errorAt(g.Position(), "previous email id %s of group %s is also a previous email id of group %s", id, g.EmailId, prev.EmailId))
+			}
 		}
 	}
-	return false
+	return errs
 }
 
-// accessSecretVersion accesses the payload for the given secret version if one exists
-// secretVersion is of the form projects/{project}/secrets/{secret}/versions/{version}
-func accessSecretVersion(secretVersion string) ([]byte, error) {
-	ctx := context.Background()
-	client, err := secretmanager.NewClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create secretmanager client: %w", err)
-	}
-
-	req := &secretmanagerpb.AccessSecretVersionRequest{
-		Name: secretVersion,
+// checkDeliverySettings returns an error for each delivery setting of the
+// group that is invalid or refers to an address not listed in the group.
+func checkDeliverySettings(g GoogleGroup) []error {
+	var errs []error
+	emails := append(append(append([]string{}, g.Owners...), g.Managers...), g.Members...)
+	for email, delivery := range g.Delivery {
+		switch delivery {
+		case AllMailDelivery, DigestDelivery, DailyDelivery, NoneDelivery, DisabledDelivery:
+		default:
+			errs = append(errs, errorAt(g.DeliveryPosition(email), "group %q has invalid delivery %q for %q", g.EmailId, delivery, email))
+		}
+		found := false
+		for _, e := range emails {
+			if EmailAddressEquals(e, email) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, errorAt(g.DeliveryPosition(email), "group %q has delivery for %q, which is not an owner, manager or member", g.EmailId, email))
+		}
 	}
+	return errs
+}
 
-	result, err := client.AccessSecretVersion(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to access secret version: %w", err)
+func matchesRegexList(s string, list []*regexp.Regexp) bool {
+	for _, r := range list {
+		if r.MatchString(s) {
+			return true
+		}
 	}
-
-	return result.Payload.Data, nil
+	return false
 }
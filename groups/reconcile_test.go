@@ -17,12 +17,20 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	admin "google.golang.org/api/admin/directory/v1"
 	groupssettings "google.golang.org/api/groupssettings/v1"
+	"gopkg.in/yaml.v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/k8s.io/groups/fake"
 )
 
@@ -63,31 +71,40 @@ func (s state) isReconciled(desiredState []GoogleGroup) error {
 		// safe to assume that the EmailID will exist as a key because
 		// we already checked if the groups that exist are the same or
 		// not.
-		currGroup := currGroups[desiredState[i].EmailId]
-		desiredMembers := constructMemberListFromGoogleGroup(desiredState[i])
-		currentMembers, err := s.adminClient.ListMembers(currGroup.Email)
-		if err != nil {
+		if err := s.isGroupReconciled(currGroups[desiredState[i].EmailId], desiredState[i]); err != nil {
 			return err
 		}
-		if !checkForMemberListEquality(desiredMembers, currentMembers) {
-			return fmt.Errorf(
-				"member lists do not match (email, role): desired: %#v, actual: %#v",
-				getMemberListInPrintableForm(desiredMembers),
-				getMemberListInPrintableForm(currentMembers),
-			)
-		}
-		currentSettings, err := s.groupClient.Get(currGroup.Email)
-		if err != nil {
-			return err
-		}
-		desiredSettings := constructGroupSettingsFromGoogleGroup(desiredState[i])
-		if !reflect.DeepEqual(desiredSettings, currentSettings) {
-			return fmt.Errorf(
-				"group settings do not match, desired: %#v, actual: %#v",
-				desiredSettings,
-				currentSettings,
-			)
-		}
+	}
+
+	return nil
+}
+
+// isGroupReconciled checks if the members and settings of currGroup match
+// those of desiredGroup.
+func (s state) isGroupReconciled(currGroup *admin.Group, desiredGroup GoogleGroup) error {
+	desiredMembers := constructMemberListFromGoogleGroup(desiredGroup)
+	currentMembers, err := s.adminClient.ListMembers(currGroup.Email)
+	if err != nil {
+		return err
+	}
+	if !checkForMemberListEquality(desiredMembers, currentMembers) {
+		return fmt.Errorf(
+			"member lists do not match (email, role): desired: %#v, actual: %#v",
+			getMemberListInPrintableForm(desiredMembers),
+			getMemberListInPrintableForm(currentMembers),
+		)
+	}
+	currentSettings, err := s.groupClient.Get(currGroup.Email)
+	if err != nil {
+		return err
+	}
+	desiredSettings := constructGroupSettingsFromGoogleGroup(desiredGroup)
+	if !reflect.DeepEqual(desiredSettings, currentSettings) {
+		return fmt.Errorf(
+			"group settings do not match, desired: %#v, actual: %#v",
+			desiredSettings,
+			currentSettings,
+		)
 	}
 
 	return nil
@@ -97,15 +114,15 @@ func constructMemberListFromGoogleGroup(g GoogleGroup) []*admin.Member {
 	res := make([]*admin.Member, len(g.Members)+len(g.Managers)+len(g.Owners))
 	index := 0
 	for _, m := range g.Members {
-		res[index] = &admin.Member{Email: m, Id: m, Role: MemberRole}
+		res[index] = &admin.Member{Email: m, Id: m, Role: MemberRole, DeliverySettings: g.DeliveryFor(m)}
 		index++
 	}
 	for _, m := range g.Managers {
-		res[index] = &admin.Member{Email: m, Id: m, Role: ManagerRole}
+		res[index] = &admin.Member{Email: m, Id: m, Role: ManagerRole, DeliverySettings: g.DeliveryFor(m)}
 		index++
 	}
 	for _, m := range g.Owners {
-		res[index] = &admin.Member{Email: m, Id: m, Role: OwnerRole}
+		res[index] = &admin.Member{Email: m, Id: m, Role: OwnerRole, DeliverySettings: g.DeliveryFor(m)}
 		index++
 	}
 
@@ -217,6 +234,7 @@ func TestRestrictionForPath(t *testing.T) {
 
 func TestReconcileGroups(t *testing.T) {
 	config.ConfirmChanges = true
+	deleteRightAway(t)
 	cases := []struct {
 		desc string
 		// desired state is not nescessarily the same as expected state.
@@ -230,6 +248,7 @@ func TestReconcileGroups(t *testing.T) {
 		shouldConsiderExpectedState bool
 		desiredState                []GoogleGroup
 		expectedState               []GoogleGroup
+		expectedErr                 bool
 	}{
 		{
 			desc: "state matches, nothing to reconcile",
@@ -331,6 +350,27 @@ func TestReconcileGroups(t *testing.T) {
 					Members:  []string{"m1-group1@email.com"},
 					Managers: []string{}, // member removed
 				},
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: map[string]string{
+						"AllowExternalMembers":     "true",
+						"WhoCanJoin":               "INVITED_CAN_JOIN",
+						"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+						"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+						"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+						"WhoCanModerateMembers":    "OWNERS_ONLY",
+						"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+						"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+						"MessageModerationLevel":   "MODERATE_NONE",
+						"MembersCanPostAsTheGroup": "false",
+					},
+					Owners: []string{"m1-group2@email.com"}, // owner replaced
+				},
+			},
+		},
+		{
+			desc: "last member with OWNER role deleted from group2, skip removing it",
+			desiredState: []GoogleGroup{
 				{
 					EmailId: "group2@email.com", Name: "group2", Description: "group2",
 					Settings: map[string]string{
@@ -349,6 +389,26 @@ func TestReconcileGroups(t *testing.T) {
 					Owners:  []string{}, // member removed
 				},
 			},
+			shouldConsiderExpectedState: true,
+			expectedState: []GoogleGroup{
+				{
+					EmailId: "group2@email.com", Name: "group2", Description: "group2",
+					Settings: map[string]string{
+						"AllowExternalMembers":     "true",
+						"WhoCanJoin":               "INVITED_CAN_JOIN",
+						"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+						"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+						"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+						"WhoCanModerateMembers":    "OWNERS_ONLY",
+						"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+						"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+						"MessageModerationLevel":   "MODERATE_NONE",
+						"MembersCanPostAsTheGroup": "false",
+					},
+					Members: []string{"m1-group2@email.com"},
+					Owners:  []string{"m2-group2@email.com"},
+				},
+			},
 		},
 		{
 			desc: "member with MANAGER role deleted in group1 and member with MEMBER role added in group2, attempt to reconcile",
@@ -668,6 +728,46 @@ func TestReconcileGroups(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "group2 renamed to group3, keep its members and settings",
+			desiredState: []GoogleGroup{
+				{
+					EmailId: "group1@email.com", Name: "group1", Description: "group1",
+					Settings: map[string]string{
+						"AllowExternalMembers":     "true",
+						"WhoCanJoin":               "CAN_REQUEST_TO_JOIN",
+						"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+						"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+						"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+						"WhoCanModerateMembers":    "OWNERS_AND_MANAGERS",
+						"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+						"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+						"MessageModerationLevel":   "MODERATE_NONE",
+						"MembersCanPostAsTheGroup": "true",
+					},
+					Members:  []string{"m1-group1@email.com"},
+					Managers: []string{"m2-group1@email.com"},
+				},
+				{
+					EmailId: "group3@email.com", PreviousEmailIds: []string{"group2@email.com"},
+					Name: "group3", Description: "group3",
+					Settings: map[string]string{
+						"AllowExternalMembers":     "true",
+						"WhoCanJoin":               "INVITED_CAN_JOIN",
+						"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+						"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+						"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+						"WhoCanModerateMembers":    "OWNERS_ONLY",
+						"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+						"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+						"MessageModerationLevel":   "MODERATE_NONE",
+						"MembersCanPostAsTheGroup": "false",
+					},
+					Members: []string{"m1-group2@email.com"},
+					Owners:  []string{"m2-group2@email.com"},
+				},
+			},
+		},
 	}
 
 	errFunc := func(err error) bool {
@@ -684,14 +784,18 @@ func TestReconcileGroups(t *testing.T) {
 				fakeGroupClient.GsGroups[groupKey] = &groupssettings.Groups{}
 			}
 		})
+		fakeAdminClient.RegisterRenameCallback(func(from, to string) {
+			fakeGroupClient.GsGroups[to] = fakeGroupClient.GsGroups[from]
+			delete(fakeGroupClient.GsGroups, from)
+		})
 
 		adminSvc, _ := NewAdminServiceWithClientAndErrFunc(fakeAdminClient, errFunc)
 		groupSvc, _ := NewGroupServiceWithClientAndErrFunc(fakeGroupClient, errFunc)
 
-		reconciler := &Reconciler{adminService: adminSvc, groupService: groupSvc, numWorkers: 5}
+		reconciler := &Reconciler{adminService: adminSvc, groupService: groupSvc, numWorkers: 5, snapshots: memorySnapshotStore{}}
 		err := reconciler.ReconcileGroups(c.desiredState)
-		if err != nil {
-			t.Errorf("error reconciling groups for case %s: %s", c.desc, err.Error())
+		if c.expectedErr != (err != nil) {
+			t.Errorf("expected error %v reconciling groups for case %s, got: %v", c.expectedErr, c.desc, err)
 		}
 
 		s := state{adminClient: fakeAdminClient, groupClient: fakeGroupClient}
@@ -707,3 +811,498 @@ func TestReconcileGroups(t *testing.T) {
 		}
 	}
 }
+
+func TestReconcileGroupsPartialFailure(t *testing.T) {
+	config.ConfirmChanges = true
+	desiredState := func() []GoogleGroup {
+		return []GoogleGroup{
+			{
+				EmailId: "group1@email.com", Name: "group1", Description: "group1",
+				Settings: map[string]string{
+					"AllowExternalMembers":     "true",
+					"WhoCanJoin":               "CAN_REQUEST_TO_JOIN",
+					"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+					"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+					"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+					"WhoCanModerateMembers":    "OWNERS_AND_MANAGERS",
+					"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+					"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+					"MessageModerationLevel":   "MODERATE_NONE",
+					"MembersCanPostAsTheGroup": "true",
+				},
+				Members:  []string{"m1-group1@email.com"},
+				Managers: []string{"m2-group1@email.com"},
+				Owners:   []string{"m3-group1@email.com"}, // member added
+			},
+			{
+				EmailId: "group2@email.com", Name: "group2", Description: "group2",
+				Settings: map[string]string{
+					"AllowExternalMembers":     "true",
+					"WhoCanJoin":               "INVITED_CAN_JOIN",
+					"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+					"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+					"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+					"WhoCanModerateMembers":    "OWNERS_AND_MANAGERS", // setting changed
+					"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+					"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+					"MessageModerationLevel":   "MODERATE_NONE",
+					"MembersCanPostAsTheGroup": "false",
+				},
+				Members:  []string{"m1-group2@email.com"},
+				Managers: []string{"m3-group2@email.com"}, // member added
+				Owners:   []string{"m2-group2@email.com"},
+			},
+			{
+				// group added
+				EmailId: "group3@email.com", Name: "group3", Description: "group3",
+				Settings: map[string]string{},
+				Members:  []string{"m1-group3@email.com"},
+				Owners:   []string{"m2-group3@email.com"},
+			},
+		}
+	}
+
+	cases := []struct {
+		desc    string
+		faults  []fake.Fault
+		latency time.Duration
+		// expectedErrs is the minimum number of errors aggregated by the
+		// first, faulty, reconcile.
+		expectedErrs int
+		// reconciledGroups are the groups expected to be reconciled by the
+		// first reconcile despite the faults.
+		reconciledGroups []string
+	}{
+		{
+			desc: "adding a member to group1 fails with 500, other groups reconcile",
+			faults: []fake.Fault{
+				{Method: "InsertMember", GroupKey: "group1@email.com", Code: http.StatusInternalServerError},
+			},
+			expectedErrs:     1,
+			reconciledGroups: []string{"group2@email.com", "group3@email.com"},
+		},
+		{
+			desc: "updating group2 settings is rate limited with 429, other groups reconcile",
+			faults: []fake.Fault{
+				{Method: "Patch", GroupKey: "group2@email.com", Code: http.StatusTooManyRequests},
+			},
+			expectedErrs:     1,
+			reconciledGroups: []string{"group1@email.com", "group3@email.com"},
+		},
+		{
+			desc: "creating group3 conflicts with 409, other groups reconcile",
+			faults: []fake.Fault{
+				{Method: "InsertGroup", GroupKey: "group3@email.com", Code: http.StatusConflict},
+			},
+			expectedErrs:     1,
+			reconciledGroups: []string{"group1@email.com", "group2@email.com"},
+		},
+		{
+			desc: "listing group2 members returns 404, group2 is skipped without error",
+			faults: []fake.Fault{
+				{Method: "ListMembers", GroupKey: "group2@email.com", Code: http.StatusNotFound},
+			},
+			reconciledGroups: []string{"group1@email.com", "group3@email.com"},
+		},
+		{
+			desc: "every other call fails with 500 after a delay",
+			faults: []fake.Fault{
+				{Every: 2, Code: http.StatusInternalServerError},
+			},
+			latency:      time.Millisecond,
+			expectedErrs: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			groupsConfig.Groups = desiredState()
+			fakeAdminClient := fake.NewAugmentedFakeAdminServiceClient()
+			fakeGroupClient := fake.NewAugmentedFakeGroupServiceClient()
+
+			fakeAdminClient.RegisterCallback(func(groupKey string) {
+				_, ok := fakeGroupClient.GsGroups[groupKey]
+				if !ok {
+					fakeGroupClient.GsGroups[groupKey] = &groupssettings.Groups{}
+				}
+			})
+			for _, f := range c.faults {
+				fakeAdminClient.AddFault(f)
+				fakeGroupClient.AddFault(f)
+			}
+			fakeAdminClient.SetLatency(c.latency)
+			fakeGroupClient.SetLatency(c.latency)
+
+			// use the default check for 404s from the API, which the fakes
+			// return as *googleapi.Error.
+			adminSvc, _ := NewAdminServiceWithClient(fakeAdminClient)
+			groupSvc, _ := NewGroupServiceWithClient(fakeGroupClient)
+			reconciler := &Reconciler{adminService: adminSvc, groupService: groupSvc, numWorkers: 5}
+
+			err := reconciler.ReconcileGroups(desiredState())
+			if c.expectedErrs == 0 && err != nil {
+				t.Errorf("unexpected error reconciling groups: %v", err)
+			}
+			if c.expectedErrs > 0 {
+				var agg utilerrors.Aggregate
+				if !errors.As(err, &agg) {
+					t.Fatalf("expected aggregated errors, got: %v", err)
+				}
+				if len(agg.Errors()) < c.expectedErrs {
+					t.Errorf("expected at least %d errors, got: %v", c.expectedErrs, agg)
+				}
+			}
+
+			s := state{adminClient: fakeAdminClient, groupClient: fakeGroupClient}
+			fakeAdminClient.ClearFaults()
+			fakeGroupClient.ClearFaults()
+			for _, g := range desiredState() {
+				for _, email := range c.reconciledGroups {
+					if g.EmailId != email {
+						continue
+					}
+					currGroup, err := fakeAdminClient.GetGroup(email)
+					if err != nil {
+						t.Fatalf("group %s was not created: %v", email, err)
+					}
+					if err := s.isGroupReconciled(currGroup, g); err != nil {
+						t.Errorf("group %s not reconciled despite faults in other groups: %v", email, err)
+					}
+				}
+			}
+
+			// re-running without faults converges to the desired state
+			if err := reconciler.ReconcileGroups(desiredState()); err != nil {
+				t.Errorf("error re-reconciling groups: %v", err)
+			}
+			if err := s.isReconciled(desiredState()); err != nil {
+				t.Errorf("re-reconciliation did not converge: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigLoad(t *testing.T) {
+	testcases := []struct {
+		name            string
+		content         string
+		expectedTenants []Tenant
+		expectedErr     bool
+	}{
+		{
+			name: "single tenant",
+			content: `
+bot-id: bot@kubernetes.io
+secret-version: projects/p/secrets/s/versions/latest
+groups-path: .
+`,
+			expectedTenants: []Tenant{
+				{
+					Name:             defaultTenantName,
+					BotID:            "bot@kubernetes.io",
+					SecretVersion:    "projects/p/secrets/s/versions/latest",
+					GroupsPath:       "{dir}",
+					RestrictionsPath: "{dir}/restrictions.yaml",
+				},
+			},
+		},
+		{
+			name: "multiple tenants",
+			content: `
+tenants:
+- domain: kubernetes.io
+  bot-id: bot@kubernetes.io
+  secret-version: projects/p/secrets/s/versions/latest
+- name: other
+  domain: other.io
+  bot-id: bot@other.io
+  credential-source: key-file
+  key-file: other/key.json
+  groups-path: other
+  restrictions-path: other/restrictions.yaml
+`,
+			expectedTenants: []Tenant{
+				{
+					Name:             "kubernetes.io",
+					Domain:           "kubernetes.io",
+					BotID:            "bot@kubernetes.io",
+					SecretVersion:    "projects/p/secrets/s/versions/latest",
+					GroupsPath:       "{dir}",
+					RestrictionsPath: "{dir}/restrictions.yaml",
+				},
+				{
+					Name:             "other",
+					Domain:           "other.io",
+					BotID:            "bot@other.io",
+					CredentialSource: KeyFileCredentialSource,
+					KeyFile:          "{dir}/other/key.json",
+					GroupsPath:       "{dir}/other",
+					RestrictionsPath: "{dir}/other/restrictions.yaml",
+				},
+			},
+		},
+		{
+			name: "deletion grace period and snapshot paths",
+			content: `
+tenants:
+- domain: kubernetes.io
+  deletion-grace-period: 168h
+  snapshot-path: gs://bucket/groups
+- domain: other.io
+  deletion-grace-period: 0s
+  snapshot-path: snapshots
+`,
+			expectedTenants: []Tenant{
+				{
+					Name:                "kubernetes.io",
+					Domain:              "kubernetes.io",
+					GroupsPath:          "{dir}",
+					RestrictionsPath:    "{dir}/restrictions.yaml",
+					DeletionGracePeriod: durationPtr(7 * 24 * time.Hour),
+					SnapshotPath:        "gs://bucket/groups",
+				},
+				{
+					Name:                "other.io",
+					Domain:              "other.io",
+					GroupsPath:          "{dir}",
+					RestrictionsPath:    "{dir}/restrictions.yaml",
+					DeletionGracePeriod: durationPtr(0),
+					SnapshotPath:        "{dir}/snapshots",
+				},
+			},
+		},
+		{
+			name: "negative deletion grace period",
+			content: `
+deletion-grace-period: -1h
+`,
+			expectedErr: true,
+		},
+		{
+			name: "tenants and top-level tenant fields",
+			content: `
+bot-id: bot@kubernetes.io
+tenants:
+- domain: kubernetes.io
+`,
+			expectedErr: true,
+		},
+		{
+			name: "tenant without name or domain",
+			content: `
+tenants:
+- bot-id: bot@kubernetes.io
+`,
+			expectedErr: true,
+		},
+		{
+			name: "duplicate tenant names",
+			content: `
+tenants:
+- domain: kubernetes.io
+- name: kubernetes.io
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var c Config
+			err := c.Load(path, true)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected error, got config %#v", c)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for i := range tc.expectedTenants {
+				e := &tc.expectedTenants[i]
+				e.GroupsPath = strings.ReplaceAll(e.GroupsPath, "{dir}", dir)
+				e.RestrictionsPath = strings.ReplaceAll(e.RestrictionsPath, "{dir}", dir)
+				e.KeyFile = strings.ReplaceAll(e.KeyFile, "{dir}", dir)
+				e.SnapshotPath = strings.ReplaceAll(e.SnapshotPath, "{dir}", dir)
+			}
+			if !reflect.DeepEqual(tc.expectedTenants, c.Tenants) {
+				t.Errorf("expected tenants %#v, got %#v", tc.expectedTenants, c.Tenants)
+			}
+			if !c.ConfirmChanges {
+				t.Errorf("expected ConfirmChanges to be set")
+			}
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestCheckGroupDomains(t *testing.T) {
+	groups := []GoogleGroup{
+		{EmailId: "group1@kubernetes.io"},
+		{EmailId: "group2@Kubernetes.io"},
+		{EmailId: "group3@other.io"},
+	}
+
+	tenant := Tenant{Name: "any"}
+	if err := tenant.CheckGroupDomains(groups); err != nil {
+		t.Errorf("unexpected error for tenant without domain: %v", err)
+	}
+
+	tenant = Tenant{Name: "k8s", Domain: "kubernetes.io"}
+	err := tenant.CheckGroupDomains(groups)
+	if err == nil || !strings.Contains(err.Error(), "group3@other.io") {
+		t.Errorf("expected error for group3@other.io, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "group2") {
+		t.Errorf("unexpected error for group2: %v", err)
+	}
+
+	groups = []GoogleGroup{
+		{EmailId: "group1@kubernetes.io", PreviousEmailIds: []string{"group0@kubernetes.io", "group1@other.io"}},
+	}
+	err = tenant.CheckGroupDomains(groups)
+	if err == nil || !strings.Contains(err.Error(), "previous email id group1@other.io") {
+		t.Errorf("expected error for group1@other.io, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "group0") {
+		t.Errorf("unexpected error for group0: %v", err)
+	}
+}
+
+func TestCheckPreviousEmailIds(t *testing.T) {
+	groups := []GoogleGroup{
+		{EmailId: "group1@example.com", PreviousEmailIds: []string{"group0@example.com"}},
+		{EmailId: "group2@example.com", PreviousEmailIds: []string{"Group1@example.com"}},
+		{EmailId: "group3@example.com", PreviousEmailIds: []string{"group0@example.com", "group3@example.com"}},
+		{EmailId: "group4@example.com", PreviousEmailIds: []string{"old4@example.com"}},
+	}
+	var actual []string
+	for _, err := range checkPreviousEmailIds(groups) {
+		actual = append(actual, err.Error())
+	}
+	expected := []string{
+		"previous email id Group1@example.com of group group2@example.com is the email id of a group",
+		"previous email id group0@example.com of group group3@example.com is also a previous email id of group group1@example.com",
+		"group group3@example.com lists group3@example.com as a previous email id more than once or as its own",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestCheckDeliverySettings(t *testing.T) {
+	cases := []struct {
+		desc         string
+		delivery     map[string]string
+		expectedErrs int
+	}{
+		{
+			desc:     "no delivery",
+			delivery: nil,
+		},
+		{
+			desc: "valid delivery for owners, managers and members",
+			delivery: map[string]string{
+				"owner@example.com":   AllMailDelivery,
+				"Manager@example.com": DigestDelivery,
+				"member@example.com":  DisabledDelivery,
+			},
+		},
+		{
+			desc:         "invalid delivery",
+			delivery:     map[string]string{"member@example.com": "WEEKLY"},
+			expectedErrs: 1,
+		},
+		{
+			desc:         "delivery for an address not in the group",
+			delivery:     map[string]string{"other@example.com": NoneDelivery},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			g := GoogleGroup{
+				EmailId:  "group@example.com",
+				Owners:   []string{"owner@example.com"},
+				Managers: []string{"manager@example.com"},
+				Members:  []string{"member@example.com"},
+				Delivery: c.delivery,
+			}
+			if errs := checkDeliverySettings(g); len(errs) != c.expectedErrs {
+				t.Errorf("expected %d errors, got %v", c.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestAnnotateMemberTypes(t *testing.T) {
+	in := `groups:
+  - email-id: group1@email.com
+    managers:
+      - m2-group1@email.com
+    members:
+      - group2@email.com
+      - m1-group1@email.com
+  - email-id: group2@email.com
+    members:
+      - m1-group2@email.com
+`
+	expected := `groups:
+  - email-id: group1@email.com
+    managers:
+      - m2-group1@email.com
+    members:
+      - group2@email.com # type: GROUP
+      - m1-group1@email.com
+  - email-id: group2@email.com
+    members:
+      - m1-group2@email.com
+`
+	actual, err := annotateMemberTypes(in, map[string]map[string]string{
+		"group1@email.com": {"group2@email.com": "GROUP"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestRenderGroupsConfig(t *testing.T) {
+	gc := GroupsConfig{Groups: []GoogleGroup{
+		{
+			EmailId: "group1@email.com",
+			Members: []string{"group2@email.com"},
+		},
+	}}
+	actual, err := renderGroupsConfig(gc, map[string]map[string]string{
+		"group1@email.com": {"group2@email.com": "GROUP"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rendered GroupsConfig
+	if err := yaml.Unmarshal([]byte(actual), &rendered); err != nil {
+		t.Fatalf("error parsing rendered groups: %v\n%s", err, actual)
+	}
+	if len(rendered.Groups) != 1 || !reflect.DeepEqual(gc.Groups[0].Members, rendered.Groups[0].Members) {
+		t.Errorf("expected the rendered groups to contain %v, got:\n%s", gc.Groups, actual)
+	}
+	if !strings.Contains(actual, "- group2@email.com # type: GROUP") {
+		t.Errorf("expected the nested group to be annotated, got:\n%s", actual)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// activeAt reports whether an address with schedule s has its role at the
+// given time.
+func (s RoleSchedule) activeAt(at time.Time) bool {
+	return (s.From == nil || !at.Before(*s.From)) && (s.Until == nil || at.Before(*s.Until))
+}
+
+// endedAt reports whether an address with schedule s lost its role by the
+// given time.
+func (s RoleSchedule) endedAt(at time.Time) bool {
+	return s.Until != nil && !at.Before(*s.Until)
+}
+
+// overlaps reports whether there is a time at which both s and o are active.
+func (s RoleSchedule) overlaps(o RoleSchedule) bool {
+	return (s.From == nil || o.Until == nil || s.From.Before(*o.Until)) &&
+		(o.From == nil || s.Until == nil || o.From.Before(*s.Until))
+}
+
+// forRole returns when an address with schedule s has the given role, and
+// whether it is scheduled in that role.
+func (s MemberSchedule) forRole(role string) (RoleSchedule, bool) {
+	if len(s.Roles) == 0 {
+		return RoleSchedule{From: s.From, Until: s.Until}, true
+	}
+	r, ok := s.Roles[role]
+	return r, ok
+}
+
+// ScheduleFor returns the schedule configured for the given address, and
+// whether it has one.
+func (g GoogleGroup) ScheduleFor(email string) (MemberSchedule, bool) {
+	for e, schedule := range g.Schedule {
+		if EmailAddressEquals(e, email) {
+			return schedule, true
+		}
+	}
+	return MemberSchedule{}, false
+}
+
+// roleScheduleFor returns when the given address has the given role, and
+// whether it is scheduled in that role.
+func (g GoogleGroup) roleScheduleFor(role, email string) (RoleSchedule, bool) {
+	s, ok := g.ScheduleFor(email)
+	if !ok {
+		return RoleSchedule{}, false
+	}
+	return s.forRole(role)
+}
+
+// asOf returns g as it is reconciled at the given time: without the owners,
+// managers and members whose schedule in their role hasn't started or has
+// ended, and without the delivery settings of the addresses it then no
+// longer lists.
+func (g GoogleGroup) asOf(at time.Time) GoogleGroup {
+	if len(g.Schedule) == 0 {
+		return g
+	}
+	var listed []string
+	for _, list := range []struct {
+		role    string
+		members *[]string
+	}{
+		{OwnerRole, &g.Owners},
+		{ManagerRole, &g.Managers},
+		{MemberRole, &g.Members},
+	} {
+		var kept []string
+		for _, m := range *list.members {
+			if s, ok := g.roleScheduleFor(list.role, m); !ok || s.activeAt(at) {
+				kept = append(kept, m)
+			}
+		}
+		*list.members = kept
+		listed = append(listed, kept...)
+	}
+	if g.Delivery != nil {
+		delivery := map[string]string{}
+		for email, d := range g.Delivery {
+			if containsEmail(listed, email) {
+				delivery[email] = d
+			}
+		}
+		g.Delivery = delivery
+	}
+	return g
+}
+
+// endedAt returns the owners, managers and members of g whose schedule has
+// ended by the given time, in every role g lists them in.
+func (g GoogleGroup) endedAt(at time.Time) []string {
+	active := g.asOf(at)
+	listed := append(append(append([]string{}, active.Owners...), active.Managers...), active.Members...)
+	var ended []string
+	for _, list := range []struct {
+		role    string
+		members []string
+	}{
+		{OwnerRole, g.Owners},
+		{ManagerRole, g.Managers},
+		{MemberRole, g.Members},
+	} {
+		for _, m := range list.members {
+			if containsEmail(listed, m) || containsEmail(ended, m) {
+				continue
+			}
+			if s, ok := g.roleScheduleFor(list.role, m); ok && s.endedAt(at) {
+				ended = append(ended, m)
+			}
+		}
+	}
+	return ended
+}
+
+// scheduledApart reports whether email has schedules in roles a and b of g
+// that don't overlap, so that it may be listed in both.
+func (g GoogleGroup) scheduledApart(email, a, b string) bool {
+	sa, okA := g.roleScheduleFor(a, email)
+	sb, okB := g.roleScheduleFor(b, email)
+	return okA && okB && a != b && !sa.overlaps(sb)
+}
+
+// checkSchedule returns an error for each schedule of g that is for an
+// address g doesn't list or that is never active.
+func checkSchedule(g GoogleGroup) []error {
+	var errs []error
+	roles := map[string][]string{OwnerRole: g.Owners, ManagerRole: g.Managers, MemberRole: g.Members}
+	for email, s := range g.Schedule {
+		pos := g.SchedulePosition(email)
+		listedAs := map[string]bool{}
+		for role, members := range roles {
+			if containsEmail(members, email) {
+				listedAs[role] = true
+			}
+		}
+		if len(listedAs) == 0 {
+			errs = append(errs, errorAt(pos, "group %q has a schedule for %q, which is not an owner, manager or member", g.EmailId, email))
+		}
+		if len(s.Roles) == 0 {
+			errs = append(errs, checkRoleSchedule(g, pos, email, "", RoleSchedule{From: s.From, Until: s.Until})...)
+			continue
+		}
+		if s.From != nil || s.Until != nil {
+			errs = append(errs, errorAt(pos, "group %q has a schedule for %q with both roles and from or until", g.EmailId, email))
+		}
+		for _, role := range []string{OwnerRole, ManagerRole, MemberRole} {
+			rs, ok := s.Roles[role]
+			if !ok {
+				continue
+			}
+			errs = append(errs, checkRoleSchedule(g, pos, email, " as "+role, rs)...)
+			if len(listedAs) > 0 && !listedAs[role] {
+				errs = append(errs, errorAt(pos, "group %q has a schedule for %q as %s, which it is not listed as", g.EmailId, email, role))
+			}
+		}
+	}
+	return errs
+}
+
+// checkRoleSchedule returns an error if schedule s of email, in the role
+// described by as, is never active.
+func checkRoleSchedule(g GoogleGroup, pos Position, email, as string, s RoleSchedule) []error {
+	switch {
+	case s.From == nil && s.Until == nil:
+		return []error{errorAt(pos, "group %q has a schedule for %q%s without from or until", g.EmailId, email, as)}
+	case s.From != nil && s.Until != nil && !s.Until.After(*s.From):
+		return []error{errorAt(pos, "group %q has a schedule for %q%s whose until isn't after its from", g.EmailId, email, as)}
+	}
+	return nil
+}
+
+// Transition is a scheduled change of the owners, managers or members of a
+// group.
+type Transition struct {
+	// Time is when the change takes effect, on the first run after it.
+	Time time.Time
+	// Group is the email of the group.
+	Group string
+	// Role is the role of Email in Group.
+	Role string
+	// Email is the address added or removed.
+	Email string
+	// Added is true if Email is added, false if it is removed.
+	Added bool
+	// Pos is where the schedule of Email is defined.
+	Pos Position
+}
+
+// scheduledTransitions returns the transitions of groups taking effect
+// after the given time, in the order they do.
+func scheduledTransitions(groups []GoogleGroup, after time.Time) []Transition {
+	var res []Transition
+	for _, g := range groups {
+		for _, list := range []struct {
+			role    string
+			members []string
+		}{
+			{OwnerRole, g.Owners},
+			{ManagerRole, g.Managers},
+			{MemberRole, g.Members},
+		} {
+			for _, m := range list.members {
+				s, ok := g.roleScheduleFor(list.role, m)
+				if !ok {
+					continue
+				}
+				t := Transition{Group: g.EmailId, Role: list.role, Email: m, Pos: g.SchedulePosition(m)}
+				if s.From != nil && s.From.After(after) {
+					t.Time, t.Added = *s.From, true
+					res = append(res, t)
+				}
+				if s.Until != nil && s.Until.After(after) {
+					t.Time, t.Added = *s.Until, false
+					res = append(res, t)
+				}
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if !res[i].Time.Equal(res[j].Time) {
+			return res[i].Time.Before(res[j].Time)
+		}
+		return res[i].Group < res[j].Group
+	})
+	return res
+}
+
+// printTransitions prints transitions to w, one per line, with the path of
+// the file scheduling them relative to the working directory.
+func printTransitions(w io.Writer, transitions []Transition) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, t := range transitions {
+		pos := t.Pos
+		pos.Path = relativePath(pos.Path)
+		change := "remove " + t.Email + " as " + t.Role + " from"
+		if t.Added {
+			change = "add " + t.Email + " as " + t.Role + " to"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.Time.UTC().Format(time.RFC3339), change, t.Group, pos)
+	}
+	return tw.Flush()
+}
+
+// runSchedule implements the schedule command: it prints the upcoming
+// transitions of the groups of every tenant of the config.
+func runSchedule(o *options, args []string) error {
+	fs := newFlagSet("schedule", o, false)
+	all := fs.Bool("all", false, "also print the transitions that already took effect")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("schedule takes no arguments, got %q", fs.Args())
+	}
+	after := now()
+	if *all {
+		after = time.Time{}
+	}
+
+	if err := config.Load(o.configFile, false); err != nil {
+		return err
+	}
+	var (
+		errs  []error
+		found int
+	)
+	for _, t := range config.Tenants {
+		if err := loadTenant(t); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+			continue
+		}
+		transitions := scheduledTransitions(groupsConfig.Groups, after)
+		if len(transitions) == 0 {
+			continue
+		}
+		found += len(transitions)
+		if len(config.Tenants) > 1 {
+			fmt.Printf("# tenant: %s\n", t.Name)
+		}
+		if err := printTransitions(os.Stdout, transitions); err != nil {
+			return err
+		}
+	}
+	if found == 0 && len(errs) == 0 {
+		fmt.Fprintln(os.Stderr, "no scheduled transitions")
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// scheduledGroup is a group with the schedules of the addresses it lists.
+const scheduledGroup = `email-id: group1@email.com
+owners:
+  - incoming-lead@email.com
+  - outgoing-lead@email.com
+managers:
+  - promoted@email.com
+members:
+  - m1-group1@email.com
+  - promoted@email.com
+  - shadow@email.com
+delivery:
+  incoming-lead@email.com: DIGEST
+  outgoing-lead@email.com: DIGEST
+schedule:
+  promoted@email.com:
+    roles:
+      MEMBER:
+        until: 2026-10-01T00:00:00Z
+      MANAGER:
+        from: 2026-10-01T00:00:00Z
+  incoming-lead@email.com:
+    from: 2026-10-01T00:00:00Z
+  outgoing-lead@email.com:
+    until: 2026-10-01T00:00:00Z
+  shadow@email.com:
+    from: 2026-06-01T00:00:00Z
+    until: 2026-12-01T00:00:00Z
+`
+
+func TestAsOf(t *testing.T) {
+	g := groupFromYAML(t, scheduledGroup)
+	cases := []struct {
+		desc             string
+		at               time.Time
+		expectedOwners   []string
+		expectedManagers []string
+		expectedMembers  []string
+		expectedDelivery map[string]string
+		expectedEnded    []string
+	}{
+		{
+			desc:             "before any transition",
+			at:               time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+			expectedOwners:   []string{"outgoing-lead@email.com"},
+			expectedMembers:  []string{"m1-group1@email.com", "promoted@email.com"},
+			expectedDelivery: map[string]string{"outgoing-lead@email.com": DigestDelivery},
+		},
+		{
+			desc:             "at a transition",
+			at:               time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			expectedOwners:   []string{"incoming-lead@email.com"},
+			expectedManagers: []string{"promoted@email.com"},
+			expectedMembers:  []string{"m1-group1@email.com", "shadow@email.com"},
+			expectedDelivery: map[string]string{"incoming-lead@email.com": DigestDelivery},
+			expectedEnded:    []string{"outgoing-lead@email.com"},
+		},
+		{
+			desc:             "after all transitions",
+			at:               time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedOwners:   []string{"incoming-lead@email.com"},
+			expectedManagers: []string{"promoted@email.com"},
+			expectedMembers:  []string{"m1-group1@email.com"},
+			expectedDelivery: map[string]string{"incoming-lead@email.com": DigestDelivery},
+			expectedEnded:    []string{"outgoing-lead@email.com", "shadow@email.com"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual := g.asOf(c.at)
+			if !reflect.DeepEqual(actual.Owners, c.expectedOwners) {
+				t.Errorf("expected owners %v, got %v", c.expectedOwners, actual.Owners)
+			}
+			if !reflect.DeepEqual(actual.Managers, c.expectedManagers) {
+				t.Errorf("expected managers %v, got %v", c.expectedManagers, actual.Managers)
+			}
+			if !reflect.DeepEqual(actual.Members, c.expectedMembers) {
+				t.Errorf("expected members %v, got %v", c.expectedMembers, actual.Members)
+			}
+			if !reflect.DeepEqual(actual.Delivery, c.expectedDelivery) {
+				t.Errorf("expected delivery %v, got %v", c.expectedDelivery, actual.Delivery)
+			}
+			if ended := g.endedAt(c.at); !reflect.DeepEqual(ended, c.expectedEnded) {
+				t.Errorf("expected ended %v, got %v", c.expectedEnded, ended)
+			}
+		})
+	}
+}
+
+func TestCheckSchedule(t *testing.T) {
+	cases := []struct {
+		desc         string
+		schedule     string
+		expectedErrs []string
+	}{
+		{
+			desc: "valid",
+			schedule: `  m1-group1@email.com:
+    from: 2026-06-01T00:00:00Z
+    until: 2026-12-01T00:00:00Z`,
+		},
+		{
+			desc: "not listed",
+			schedule: `  other@email.com:
+    from: 2026-06-01T00:00:00Z`,
+			expectedErrs: []string{`groups.yaml:7:3: group "group1@email.com" has a schedule for "other@email.com", which is not an owner, manager or member`},
+		},
+		{
+			desc: "neither from nor until",
+			schedule: `  M1-group1@email.com: {}
+`,
+			expectedErrs: []string{`groups.yaml:7:3: group "group1@email.com" has a schedule for "M1-group1@email.com" without from or until`},
+		},
+		{
+			desc: "valid by role",
+			schedule: `  m1-group1@email.com:
+    roles:
+      MEMBER:
+        until: 2026-06-01T00:00:00Z`,
+		},
+		{
+			desc: "role not listed",
+			schedule: `  m1-group1@email.com:
+    roles:
+      MANAGER:
+        from: 2026-06-01T00:00:00Z`,
+			expectedErrs: []string{`groups.yaml:7:3: group "group1@email.com" has a schedule for "m1-group1@email.com" as MANAGER, which it is not listed as`},
+		},
+		{
+			desc: "roles and until",
+			schedule: `  m1-group1@email.com:
+    until: 2026-06-01T00:00:00Z
+    roles:
+      MEMBER:
+        until: 2026-06-01T00:00:00Z`,
+			expectedErrs: []string{`groups.yaml:7:3: group "group1@email.com" has a schedule for "m1-group1@email.com" with both roles and from or until`},
+		},
+		{
+			desc: "role without from or until",
+			schedule: `  m1-group1@email.com:
+    roles:
+      MEMBER: {}`,
+			expectedErrs: []string{`groups.yaml:7:3: group "group1@email.com" has a schedule for "m1-group1@email.com" as MEMBER without from or until`},
+		},
+		{
+			desc: "until before from",
+			schedule: `  m1-group1@email.com:
+    from: 2026-12-01T00:00:00Z
+    until: 2026-06-01T00:00:00Z`,
+			expectedErrs: []string{`groups.yaml:7:3: group "group1@email.com" has a schedule for "m1-group1@email.com" whose until isn't after its from`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			g := groupFromYAML(t, "email-id: group1@email.com\nowners:\n  - owner@email.com\nmembers:\n  - m1-group1@email.com\nschedule:\n"+c.schedule)
+			g.setPath("groups.yaml")
+			var actual []string
+			for _, err := range checkSchedule(g) {
+				actual = append(actual, err.Error())
+			}
+			if !reflect.DeepEqual(actual, c.expectedErrs) {
+				t.Errorf("expected errors %v, got %v", c.expectedErrs, actual)
+			}
+		})
+	}
+}
+
+func TestScheduledTransitions(t *testing.T) {
+	g := groupFromYAML(t, scheduledGroup)
+	g.setPath("groups.yaml")
+
+	var out strings.Builder
+	if err := printTransitions(&out, scheduledTransitions([]GoogleGroup{g}, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+	expected := `2026-10-01T00:00:00Z  add incoming-lead@email.com as OWNER to       group1@email.com  groups.yaml:21:3
+2026-10-01T00:00:00Z  remove outgoing-lead@email.com as OWNER from  group1@email.com  groups.yaml:23:3
+2026-10-01T00:00:00Z  add promoted@email.com as MANAGER to          group1@email.com  groups.yaml:15:3
+2026-10-01T00:00:00Z  remove promoted@email.com as MEMBER from      group1@email.com  groups.yaml:15:3
+2026-12-01T00:00:00Z  remove shadow@email.com as MEMBER from        group1@email.com  groups.yaml:25:3
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	if all := scheduledTransitions([]GoogleGroup{g}, time.Time{}); len(all) != 6 {
+		t.Errorf("expected all 6 transitions, got %v", all)
+	}
+}
+
+func TestReconcileScheduledMembers(t *testing.T) {
+	stubNow(t, time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC))
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+	}()
+
+	// m1-group1 is a member whose schedule ended, which is removed although
+	// group1 doesn't reconcile members, as is the manager m2-group1, while
+	// promoted changed from member to manager and is kept.
+	g := groupFromYAML(t, `email-id: group1@email.com
+managers:
+  - m2-group1@email.com
+  - promoted@email.com
+members:
+  - m1-group1@email.com
+  - current@email.com
+  - future@email.com
+  - promoted@email.com
+schedule:
+  promoted@email.com:
+    roles:
+      MEMBER:
+        until: 2026-10-01T00:00:00Z
+      MANAGER:
+        from: 2026-10-01T00:00:00Z
+  m1-group1@email.com:
+    until: 2026-10-01T00:00:00Z
+  m2-group1@email.com:
+    until: 2026-10-19T10:00:00Z
+  current@email.com:
+    from: 2026-10-01T00:00:00Z
+  future@email.com:
+    from: 2026-11-01T00:00:00Z
+`)
+	groupsConfig.Groups = []GoogleGroup{g, {EmailId: "group2@email.com"}}
+	r, fakeAdminClient, _ := newLifecycleReconciler("", memorySnapshotStore{})
+	if _, err := fakeAdminClient.InsertMember("group1@email.com", &admin.Member{Email: "promoted@email.com", Id: "promoted@email.com", Role: MemberRole}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ReconcileGroups([]GoogleGroup{g}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var actual []string
+	for email := range fakeAdminClient.Members["group1@email.com"] {
+		actual = append(actual, email)
+	}
+	sort.Strings(actual)
+	expected := []string{"current@email.com", "promoted@email.com"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected members %v, got %v", expected, actual)
+	}
+	if role := fakeAdminClient.Members["group1@email.com"]["promoted@email.com"].Role; role != ManagerRole {
+		t.Errorf("expected promoted@email.com to be a %s, got %s", ManagerRole, role)
+	}
+}
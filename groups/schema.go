@@ -0,0 +1,371 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// GroupsSchemaFile and RestrictionsSchemaFile are the files holding the
+	// schemas of groups.yaml and restrictions.yaml files, generated by the
+	// schema command.
+	GroupsSchemaFile       = "groups.schema.json"
+	RestrictionsSchemaFile = "restrictions.schema.json"
+)
+
+// Schema is a JSON Schema, limited to the keywords needed to describe the
+// groups and restrictions configs.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// never makes this the false schema, which nothing validates against.
+	never bool
+}
+
+// falseSchema is used as AdditionalProperties to reject unknown properties.
+var falseSchema = &Schema{never: true}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+var (
+	trueOrFalse = []string{"true", "false"}
+
+	// settingsEnums are the values allowed for each of the settings of a
+	// group, see
+	// https://developers.google.com/admin-sdk/groups-settings/v1/reference/groups
+	settingsEnums = map[string][]string{
+		"AllowExternalMembers":     trueOrFalse,
+		"AllowWebPosting":          trueOrFalse,
+		"MembersCanPostAsTheGroup": trueOrFalse,
+		"ReconcileMembers":         trueOrFalse,
+		"WhoCanJoin":               {"ANYONE_CAN_JOIN", "ALL_IN_DOMAIN_CAN_JOIN", "INVITED_CAN_JOIN", "CAN_REQUEST_TO_JOIN"},
+		"WhoCanViewMembership":     {"ALL_IN_DOMAIN_CAN_VIEW", "ALL_MEMBERS_CAN_VIEW", "ALL_MANAGERS_CAN_VIEW", "ALL_OWNERS_CAN_VIEW"},
+		"WhoCanViewGroup":          {"ANYONE_CAN_VIEW", "ALL_IN_DOMAIN_CAN_VIEW", "ALL_MEMBERS_CAN_VIEW", "ALL_MANAGERS_CAN_VIEW", "ALL_OWNERS_CAN_VIEW"},
+		"WhoCanDiscoverGroup":      {"ANYONE_CAN_DISCOVER", "ALL_IN_DOMAIN_CAN_DISCOVER", "ALL_MEMBERS_CAN_DISCOVER"},
+		"WhoCanModerateMembers":    {"ALL_MEMBERS", "OWNERS_AND_MANAGERS", "OWNERS_ONLY", "NONE"},
+		"WhoCanModerateContent":    {"ALL_MEMBERS", "OWNERS_AND_MANAGERS", "OWNERS_ONLY", "NONE"},
+		"WhoCanPostMessage":        {"NONE_CAN_POST", "ALL_MANAGERS_CAN_POST", "ALL_MEMBERS_CAN_POST", "ALL_OWNERS_CAN_POST", "ALL_IN_DOMAIN_CAN_POST", "ANYONE_CAN_POST"},
+		"MessageModerationLevel":   {"MODERATE_ALL_MESSAGES", "MODERATE_NON_MEMBERS", "MODERATE_NEW_MEMBERS", "MODERATE_NONE"},
+	}
+
+	deliveryEnum = []string{AllMailDelivery, DigestDelivery, DailyDelivery, NoneDelivery, DisabledDelivery}
+
+	// requiredFields lists the required fields of each type, by JSON name.
+	requiredFields = map[string][]string{
+		"GoogleGroup": {"email-id"},
+		"Restriction": {"path"},
+	}
+
+	// schemaOverrides refines the schema generated for the field of a type,
+	// keyed by type name and JSON name of the field.
+	schemaOverrides = map[string]func(*Schema){
+		"GoogleGroup.email-id":           emailSchema,
+		"GoogleGroup.previous-email-ids": func(s *Schema) { emailSchema(s.Items) },
+		"GoogleGroup.owners":             func(s *Schema) { emailSchema(s.Items) },
+		"GoogleGroup.managers":           func(s *Schema) { emailSchema(s.Items) },
+		"GoogleGroup.members":            func(s *Schema) { emailSchema(s.Items) },
+		"GoogleGroup.aliases":            func(s *Schema) { emailSchema(s.Items) },
+		"GoogleGroup.delivery": func(s *Schema) {
+			s.PropertyNames = &Schema{Format: "email"}
+			s.AdditionalProperties.Enum = deliveryEnum
+		},
+		"GoogleGroup.schedule": func(s *Schema) {
+			s.PropertyNames = &Schema{Format: "email"}
+		},
+		"MemberSchedule.roles": func(s *Schema) {
+			s.PropertyNames = &Schema{Enum: []string{OwnerRole, ManagerRole, MemberRole}}
+		},
+		"GoogleGroup.settings": func(s *Schema) {
+			s.Properties = map[string]*Schema{}
+			for name, values := range settingsEnums {
+				s.Properties[name] = &Schema{Type: "string", Enum: values}
+			}
+			s.AdditionalProperties = falseSchema
+		},
+		"Restriction.allowedGroups":               func(s *Schema) { s.Items.Format = "regex" },
+		"Restriction.minOwnersAndManagers":        nonNegativeSchema,
+		"Sensitive.groups":                        func(s *Schema) { s.Items.Format = "regex" },
+		"RestrictionsConfig.minOwnersAndManagers": nonNegativeSchema,
+	}
+)
+
+func emailSchema(s *Schema) {
+	s.Format = "email"
+}
+
+func nonNegativeSchema(s *Schema) {
+	zero := 0
+	s.Minimum = &zero
+}
+
+// GroupsSchema returns the JSON Schema of groups.yaml files.
+func GroupsSchema() *Schema {
+	s := schemaForType(reflect.TypeOf(GroupsConfig{}))
+	s.Schema = jsonSchemaDraft
+	s.Title = "groups.yaml"
+	s.Description = "Google Groups reconciled by k8s.io/groups."
+	return s
+}
+
+// RestrictionsSchema returns the JSON Schema of restrictions.yaml files.
+func RestrictionsSchema() *Schema {
+	s := schemaForType(reflect.TypeOf(RestrictionsConfig{}))
+	s.Schema = jsonSchemaDraft
+	s.Title = "restrictions.yaml"
+	s.Description = "Groups that may be defined in each groups.yaml file of k8s.io/groups."
+	return s
+}
+
+// schemaForType generates the schema of t from the json tags of its fields.
+// Fields without a json tag are skipped.
+func schemaForType(t reflect.Type) *Schema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int:
+		return &Schema{Type: "integer"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: falseSchema}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if !f.IsExported() || tag == "" || tag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			fs := schemaForType(f.Type)
+			if override, ok := schemaOverrides[t.Name()+"."+name]; ok {
+				override(fs)
+			}
+			s.Properties[name] = fs
+		}
+		s.Required = requiredFields[t.Name()]
+		return s
+	default:
+		panic(fmt.Sprintf("no schema for %v", t))
+	}
+}
+
+// emailRe is a lenient check of email addresses, which only catches obvious
+// mistakes such as a missing domain.
+var emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// Validate validates the YAML document at path against the schema, and
+// returns an error with the position of each violation.
+func (s *Schema) Validate(path string, content []byte) []error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return []error{fmt.Errorf("error parsing %s: %w", path, err)}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	return s.validate(path, "", doc.Content[0])
+}
+
+func (s *Schema) validate(path, field string, node *yaml.Node) []error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	pos := Position{Path: path, Line: node.Line, Column: node.Column}
+	if field == "" {
+		field = "document"
+	}
+	if s.never {
+		return []error{errorAt(pos, "%s is not allowed", field)}
+	}
+
+	var errs []error
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			return []error{errorAt(pos, "%s must be a mapping", field)}
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			seen[key.Value] = true
+			keyField := joinField(field, key.Value)
+			if s.PropertyNames != nil {
+				errs = append(errs, s.PropertyNames.validateString(path, keyField, key)...)
+			}
+			ps, ok := s.Properties[key.Value]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+			if ps == nil {
+				continue
+			}
+			if ps.never {
+				errs = append(errs, errorAt(Position{Path: path, Line: key.Line, Column: key.Column}, "unknown field %s", keyField))
+				continue
+			}
+			if value.Tag == "!!null" {
+				// Empty fields decode to their zero value, like omitted ones.
+				continue
+			}
+			errs = append(errs, ps.validate(path, keyField, value)...)
+		}
+		for _, name := range s.Required {
+			if !seen[name] {
+				errs = append(errs, errorAt(pos, "%s is missing required field %s", field, name))
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return []error{errorAt(pos, "%s must be a list", field)}
+		}
+		for i, item := range node.Content {
+			errs = append(errs, s.Items.validate(path, fmt.Sprintf("%s[%d]", field, i), item)...)
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return []error{errorAt(pos, "%s must be a boolean", field)}
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return []error{errorAt(pos, "%s must be an integer", field)}
+		}
+		if s.Minimum != nil {
+			if n, err := strconv.Atoi(node.Value); err == nil && n < *s.Minimum {
+				return []error{errorAt(pos, "%s must be at least %d", field, *s.Minimum)}
+			}
+		}
+	default:
+		errs = append(errs, s.validateString(path, field, node)...)
+	}
+	return errs
+}
+
+// validateString validates a scalar node against the string type, enum and
+// format of s.
+func (s *Schema) validateString(path, field string, node *yaml.Node) []error {
+	pos := Position{Path: path, Line: node.Line, Column: node.Column}
+	// Timestamps are strings in JSON, but have their own tag in YAML.
+	isString := node.Tag == "!!str" || s.Format == "date-time" && node.Tag == "!!timestamp"
+	if s.Type == "string" && (node.Kind != yaml.ScalarNode || !isString) {
+		return []error{errorAt(pos, "%s must be a string, quote it if needed", field)}
+	}
+	if len(s.Enum) > 0 {
+		valid := false
+		for _, v := range s.Enum {
+			if node.Value == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return []error{errorAt(pos, "%s must be one of %s, not %q", field, strings.Join(s.Enum, ", "), node.Value)}
+		}
+	}
+	switch s.Format {
+	case "email":
+		if !emailRe.MatchString(node.Value) {
+			return []error{errorAt(pos, "%s must be an email address, not %q", field, node.Value)}
+		}
+	case "regex":
+		if _, err := regexp.Compile(node.Value); err != nil {
+			return []error{errorAt(pos, "%s must be a regular expression: %v", field, err)}
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, node.Value); err != nil {
+			return []error{errorAt(pos, "%s must be a time like 2006-01-02T15:04:05Z, not %q", field, node.Value)}
+		}
+	}
+	return nil
+}
+
+func joinField(parent, name string) string {
+	if parent == "document" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// schemaJSON returns the indented JSON of the schema, with a trailing newline.
+func schemaJSON(s *Schema) ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// schemaNames lists the schemas printed by the schema command, by name.
+var schemaNames = map[string]func() *Schema{
+	"groups":       GroupsSchema,
+	"restrictions": RestrictionsSchema,
+}
+
+// printSchema prints the schema with the given name, see schemaNames.
+func printSchema(name string) error {
+	newSchema, ok := schemaNames[name]
+	if !ok {
+		var names []string
+		for n := range schemaNames {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown schema %q, must be one of %s", name, strings.Join(names, ", "))
+	}
+	b, err := schemaJSON(newSchema())
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
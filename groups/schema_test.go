@@ -0,0 +1,191 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestSchemaFilesUpToDate ensures the checked in schemas, used by editors,
+// match the schemas the loader validates against.
+func TestSchemaFilesUpToDate(t *testing.T) {
+	for file, schema := range map[string]*Schema{
+		GroupsSchemaFile:       GroupsSchema(),
+		RestrictionsSchemaFile: RestrictionsSchema(),
+	} {
+		expected, err := schemaJSON(schema)
+		if err != nil {
+			t.Fatalf("error generating %s: %v", file, err)
+		}
+		actual, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("error reading %s: %v", file, err)
+		}
+		if string(expected) != string(actual) {
+			t.Errorf("%s is out of date, run `make schema` to update it", file)
+		}
+	}
+}
+
+func TestGroupsSchemaValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		yaml string
+		// expectedErrs are the expected errors, without the path prefix.
+		expectedErrs []string
+	}{
+		{
+			desc: "valid",
+			yaml: `groups:
+  - email-id: group1@example.com
+    name: group1
+    owners:
+      - owner@example.com
+    members:
+    settings:
+      ReconcileMembers: "true"
+      WhoCanJoin: "INVITED_CAN_JOIN"
+    delivery:
+      owner@example.com: DIGEST
+    schedule:
+      owner@example.com:
+        from: 2026-06-01T00:00:00Z
+        until: "2026-09-01T00:00:00+02:00"
+`,
+		},
+		{
+			desc: "unknown fields",
+			yaml: `team:
+  - email-id: group1@example.com
+groups:
+  - email-id: group1@example.com
+    owner:
+      - owner@example.com
+`,
+			expectedErrs: []string{
+				"1:1: unknown field team",
+				"5:5: unknown field groups[0].owner",
+			},
+		},
+		{
+			desc: "missing email-id and invalid emails",
+			yaml: `groups:
+  - name: group1
+    members:
+      - member@example
+      - member@example.com
+  - email-id: group2
+`,
+			expectedErrs: []string{
+				"4:9: groups[0].members[0] must be an email address, not \"member@example\"",
+				"2:5: groups[0] is missing required field email-id",
+				"6:15: groups[1].email-id must be an email address, not \"group2\"",
+			},
+		},
+		{
+			desc: "invalid settings",
+			yaml: `groups:
+  - email-id: group1@example.com
+    settings:
+      ReconcileMembers: true
+      WhoCanJoin: "EVERYONE"
+      WhoCanJoinn: "ANYONE_CAN_JOIN"
+`,
+			expectedErrs: []string{
+				"4:25: groups[0].settings.ReconcileMembers must be a string, quote it if needed",
+				"5:19: groups[0].settings.WhoCanJoin must be one of ANYONE_CAN_JOIN, ALL_IN_DOMAIN_CAN_JOIN, INVITED_CAN_JOIN, CAN_REQUEST_TO_JOIN, not \"EVERYONE\"",
+				"6:7: unknown field groups[0].settings.WhoCanJoinn",
+			},
+		},
+		{
+			desc: "invalid delivery",
+			yaml: `groups:
+  - email-id: group1@example.com
+    delivery:
+      member: WEEKLY
+`,
+			expectedErrs: []string{
+				"4:7: groups[0].delivery.member must be an email address, not \"member\"",
+				"4:15: groups[0].delivery.member must be one of ALL_MAIL, DIGEST, DAILY, NONE, DISABLED, not \"WEEKLY\"",
+			},
+		},
+		{
+			desc: "invalid schedule",
+			yaml: `groups:
+  - email-id: group1@example.com
+    schedule:
+      member@example.com:
+        from: 2026-06-01
+        to: 2026-09-01T00:00:00Z
+`,
+			expectedErrs: []string{
+				"5:15: groups[0].schedule.member@example.com.from must be a time like 2006-01-02T15:04:05Z, not \"2026-06-01\"",
+				"6:9: unknown field groups[0].schedule.member@example.com.to",
+			},
+		},
+		{
+			desc: "wrong types",
+			yaml: `groups:
+  email-id: group1@example.com
+`,
+			expectedErrs: []string{
+				"2:3: groups must be a list",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var actual []string
+			for _, err := range GroupsSchema().Validate("groups.yaml", []byte(c.yaml)) {
+				actual = append(actual, strings.TrimPrefix(err.Error(), "groups.yaml:"))
+			}
+			if strings.Join(c.expectedErrs, "\n") != strings.Join(actual, "\n") {
+				t.Errorf("expected errors:\n%s\ngot:\n%s", strings.Join(c.expectedErrs, "\n"), strings.Join(actual, "\n"))
+			}
+		})
+	}
+}
+
+func TestRestrictionsSchemaValidate(t *testing.T) {
+	content := `restrictions:
+  - path: "sig-foo/groups.yaml"
+    allowedGroups:
+      - "^sig-foo@kubernetes.io$"
+      - "^sig-foo-(leads@kubernetes.io$"
+    minOwnersAndManagers: -1
+  - allowedGroups:
+      - "^sig-bar@kubernetes.io$"
+    minOwnersAndManagers: "2"
+  - path: "**/*"
+`
+	expected := []string{
+		"restrictions.yaml:5:9: restrictions[0].allowedGroups[1] must be a regular expression: error parsing regexp: missing closing ): `^sig-foo-(leads@kubernetes.io$`",
+		"restrictions.yaml:6:27: restrictions[0].minOwnersAndManagers must be at least 0",
+		"restrictions.yaml:9:27: restrictions[1].minOwnersAndManagers must be an integer",
+		"restrictions.yaml:7:5: restrictions[1] is missing required field path",
+	}
+	var actual []string
+	for _, err := range RestrictionsSchema().Validate("restrictions.yaml", []byte(content)) {
+		actual = append(actual, err.Error())
+	}
+	if strings.Join(expected, "\n") != strings.Join(actual, "\n") {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// stringList is a flag.Value collecting the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// groupSelector selects the groups to reconcile.
+type groupSelector struct {
+	// groups are the emails of the selected groups.
+	groups stringList
+	// paths are doublestar globs of the groups.yaml files, relative to the
+	// groups-path, whose groups are selected.
+	paths stringList
+	// excludes are emails of groups or globs of groups.yaml files whose
+	// groups are not selected, even if otherwise selected.
+	excludes stringList
+}
+
+// isPartial reports whether the selector may select only some groups.
+func (s groupSelector) isPartial() bool {
+	return len(s.groups) > 0 || len(s.paths) > 0 || len(s.excludes) > 0
+}
+
+// selectGroups returns the groups selected by s, among groups defined in
+// groups.yaml files under rootDir. Groups are selected if they match any
+// group or path, or all groups if there are neither, unless they match an
+// exclude.
+//
+// It returns an error for groups that aren't defined and if no group is
+// selected, to catch typos.
+func (s groupSelector) selectGroups(groups []GoogleGroup, rootDir string) ([]GoogleGroup, error) {
+	var errs []error
+	for _, email := range s.groups {
+		if !containsGroup(groups, email) {
+			errs = append(errs, fmt.Errorf("group %s is not defined in any groups.yaml file", email))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	var selected []GoogleGroup
+	for _, g := range groups {
+		path := groupsFilePath(g, rootDir)
+		include := len(s.groups) == 0 && len(s.paths) == 0 ||
+			matchesEmail(g, s.groups) || matchesGlob(path, s.paths)
+		if include && !matchesEmail(g, s.excludes) && !matchesGlob(path, s.excludes) {
+			selected = append(selected, g)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no group is selected")
+	}
+	return selected, nil
+}
+
+// groupsFilePath returns the path of the groups.yaml file defining g relative
+// to rootDir.
+func groupsFilePath(g GoogleGroup, rootDir string) string {
+	return strings.Trim(strings.TrimPrefix(g.Position().Path, rootDir), string(filepath.Separator))
+}
+
+// containsGroup reports whether groups has a group with the given email.
+func containsGroup(groups []GoogleGroup, email string) bool {
+	for _, g := range groups {
+		if EmailAddressEquals(g.EmailId, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesEmail reports whether the email of g is one of emails.
+func matchesEmail(g GoogleGroup, emails []string) bool {
+	for _, email := range emails {
+		if EmailAddressEquals(g.EmailId, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesGlob reports whether path matches one of globs.
+func matchesGlob(path string, globs []string) bool {
+	for _, glob := range globs {
+		if match, err := doublestar.Match(glob, path); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
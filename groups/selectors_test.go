@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"k8s.io/k8s.io/groups/fake"
+)
+
+func TestSelectGroups(t *testing.T) {
+	group := func(email, path string) GoogleGroup {
+		return GoogleGroup{EmailId: email, source: groupSource{pos: Position{Path: "/groups/" + path, Line: 2, Column: 5}}}
+	}
+	groups := []GoogleGroup{
+		group("sig-node@example.com", "sig-node/groups.yaml"),
+		group("sig-node-leads@example.com", "sig-node/groups.yaml"),
+		group("sig-release@example.com", "sig-release/groups.yaml"),
+		group("k8s-infra-admins@example.com", "groups.yaml"),
+	}
+
+	cases := []struct {
+		desc        string
+		selector    groupSelector
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			desc:     "groups by email",
+			selector: groupSelector{groups: stringList{"SIG-Release@example.com", "k8s-infra-admins@example.com"}},
+			expected: []string{"sig-release@example.com", "k8s-infra-admins@example.com"},
+		},
+		{
+			desc:     "groups by path",
+			selector: groupSelector{paths: stringList{"sig-node/*"}},
+			expected: []string{"sig-node@example.com", "sig-node-leads@example.com"},
+		},
+		{
+			desc:     "groups by email or path",
+			selector: groupSelector{groups: stringList{"sig-release@example.com"}, paths: stringList{"**/sig-node/groups.yaml"}},
+			expected: []string{"sig-node@example.com", "sig-node-leads@example.com", "sig-release@example.com"},
+		},
+		{
+			desc:     "excludes by email and path",
+			selector: groupSelector{excludes: stringList{"sig-node-leads@example.com", "sig-release/*"}},
+			expected: []string{"sig-node@example.com", "k8s-infra-admins@example.com"},
+		},
+		{
+			desc:        "undefined group",
+			selector:    groupSelector{groups: stringList{"sig-typo@example.com"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "nothing selected",
+			selector:    groupSelector{paths: stringList{"sig-typo/*"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			selected, err := c.selector.selectGroups(groups, "/groups")
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			var actual []string
+			for _, g := range selected {
+				actual = append(actual, g.EmailId)
+			}
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("expected groups %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReconcileGroupsSkipDeletions(t *testing.T) {
+	config.ConfirmChanges = true
+	deleteRightAway(t)
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+	}()
+
+	errFunc := func(err error) bool {
+		return err != nil
+	}
+	for _, skipDeletions := range []bool{false, true} {
+		groupsConfig.Groups = []GoogleGroup{
+			{EmailId: "group1@email.com", Members: []string{"m1-group1@email.com"}, Managers: []string{"m2-group1@email.com"}},
+		}
+		fakeAdminClient := fake.NewAugmentedFakeAdminServiceClient()
+		fakeGroupClient := fake.NewAugmentedFakeGroupServiceClient()
+		fakeAdminClient.RegisterCallback(func(groupKey string) {
+			if _, ok := fakeGroupClient.GsGroups[groupKey]; !ok {
+				fakeGroupClient.GsGroups[groupKey] = &groupssettings.Groups{}
+			}
+		})
+		adminSvc, _ := NewAdminServiceWithClientAndErrFunc(fakeAdminClient, errFunc)
+		groupSvc, _ := NewGroupServiceWithClientAndErrFunc(fakeGroupClient, errFunc)
+
+		reconciler := &Reconciler{adminService: adminSvc, groupService: groupSvc, numWorkers: 1, skipDeletions: skipDeletions, snapshots: memorySnapshotStore{}}
+		if err := reconciler.ReconcileGroups(groupsConfig.Groups); err != nil {
+			t.Errorf("skipDeletions=%v: unexpected error: %v", skipDeletions, err)
+		}
+		if _, ok := fakeAdminClient.Groups["group2@email.com"]; ok != skipDeletions {
+			t.Errorf("skipDeletions=%v: expected group2@email.com to exist: %v, got %v", skipDeletions, skipDeletions, ok)
+		}
+	}
+}
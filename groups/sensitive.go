@@ -0,0 +1,359 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// isEmpty reports whether s selects no group.
+func (s Sensitive) isEmpty() bool {
+	return len(s.Paths) == 0 && len(s.GroupsRe) == 0
+}
+
+// isSensitive reports whether the group g, defined in a groups.yaml file
+// under rootDir, is sensitive. Groups are also sensitive under their
+// previous email ids, so that renaming one doesn't escape approval.
+func (s Sensitive) isSensitive(g GoogleGroup, rootDir string) bool {
+	if matchesGlob(groupsFilePath(g, rootDir), s.Paths) {
+		return true
+	}
+	for _, email := range append([]string{g.EmailId}, g.PreviousEmailIds...) {
+		if matchesRegexList(email, s.GroupsRe) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSensitiveEmail reports whether the group with the given email is
+// sensitive when it has no definition to look at, e.g. once it is removed
+// from the groups.yaml files: if its email matches a sensitive pattern, or
+// restrictions only allow it in a sensitive path.
+func (s Sensitive) isSensitiveEmail(email string, restrictions []Restriction) bool {
+	if matchesRegexList(email, s.GroupsRe) {
+		return true
+	}
+	for _, r := range restrictions {
+		if matchesGlob(r.Path, s.Paths) && matchesRegexList(email, r.AllowedGroupsRe) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalNamespace is the namespace of the SSH signatures of approval
+// files, so that signatures made for other purposes aren't accepted.
+const approvalNamespace = "k8s-groups-approval"
+
+// sensitiveApproval approves the changes to sensitive groups it lists. It is
+// only used once its signature, <path>.sig, verifies as made by ApprovedBy.
+type sensitiveApproval struct {
+	// ApprovedBy is who approved the changes: the principal of their key in
+	// the allowed signers file of the sensitive groups.
+	ApprovedBy string `yaml:"approved-by"`
+	// Changes are the changes to sensitive groups, as printed by plan.
+	Changes string `yaml:"changes"`
+
+	// path is the path of the approval file, signed in path + ".sig", and
+	// content is what was read from it.
+	path    string
+	content []byte
+}
+
+// loadSensitiveApproval reads the approval file at path.
+func loadSensitiveApproval(path string) (*sensitiveApproval, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading approval file %s: %w", path, err)
+	}
+	var a sensitiveApproval
+	d := yaml.NewDecoder(bytes.NewReader(content))
+	d.KnownFields(true)
+	if err := d.Decode(&a); err != nil {
+		return nil, fmt.Errorf("error parsing approval file %s: %w", path, err)
+	}
+	if a.ApprovedBy == "" || strings.TrimSpace(a.Changes) == "" {
+		return nil, fmt.Errorf("invalid approval file %s: approved-by and changes must be set", path)
+	}
+	a.path, a.content = path, content
+	return &a, nil
+}
+
+// verify checks with ssh-keygen that the approval file, as it was loaded, is
+// signed by the key of ApprovedBy in the allowedSigners file.
+func (a *sensitiveApproval) verify(allowedSigners string) error {
+	if allowedSigners == "" {
+		return fmt.Errorf("can't verify approval file %s: the restrictions config sets no sensitive allowedSigners", a.path)
+	}
+	cmd := exec.Command("ssh-keygen", "-Y", "verify", "-f", allowedSigners, "-I", a.ApprovedBy, "-n", approvalNamespace, "-s", a.path+".sig")
+	cmd.Stdin = bytes.NewReader(a.content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("approval file %s is not signed by %s: %w: %s", a.path, a.ApprovedBy, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// approves reports whether a lists each group diff of changes exactly, so
+// that an approval doesn't carry over to further changes of the same groups.
+func (a *sensitiveApproval) approves(changes string) bool {
+	if a == nil {
+		return false
+	}
+	approved := map[string]bool{}
+	for _, d := range groupDiffs(a.Changes) {
+		approved[d] = true
+	}
+	for _, d := range groupDiffs(changes) {
+		if !approved[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// groupDiffs splits changes, as printed by printGroupDiff, into the diffs of
+// each group, without trailing whitespace.
+func groupDiffs(changes string) []string {
+	var (
+		diffs   []string
+		current []string
+	)
+	for _, line := range strings.Split(changes, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(line, "--- ") && len(current) > 0 {
+			diffs = append(diffs, strings.Join(current, "\n"))
+			current = nil
+		}
+		if line != "" {
+			current = append(current, line)
+		}
+	}
+	if len(current) > 0 {
+		diffs = append(diffs, strings.Join(current, "\n"))
+	}
+	return diffs
+}
+
+// holdSensitiveChanges prints the changes that reconciling groups would make
+// to sensitive groups to w, in the format of the diff command. Unless they
+// are approved, by approveAll or by approval, the sensitive groups that
+// would change are held: ReconcileGroups leaves them as they are and reports
+// an error. Nothing is held in dry-run mode.
+func (r *Reconciler) holdSensitiveChanges(w io.Writer, groups []GoogleGroup, approveAll bool, approval *sensitiveApproval) error {
+	changes, emails, err := r.sensitiveChanges(groups)
+	if err != nil {
+		return err
+	}
+	if len(emails) == 0 {
+		log.Printf("no changes to sensitive groups")
+		return nil
+	}
+	approved, err := approvesSensitiveChanges(w, changes, emails, approveAll, approval)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		r.held = emails
+	}
+	return nil
+}
+
+// holdSensitivePurges prints the deletion of the sensitive groups among
+// groups to w, in the same format as holdSensitiveChanges, and holds them
+// unless it is approved, so that PurgeGroups leaves them as they are.
+func (r *Reconciler) holdSensitivePurges(w io.Writer, groups []*admin.Group, approveAll bool, approval *sensitiveApproval) error {
+	var (
+		b      strings.Builder
+		emails []string
+	)
+	for _, g := range groups {
+		if !restrictionsConfig.Sensitive.isSensitiveEmail(g.Email, restrictionsConfig.Restrictions) {
+			continue
+		}
+		if _, err := printGroupDiff(&b, g.Email, &GoogleGroup{EmailId: g.Email}, nil); err != nil {
+			return err
+		}
+		emails = append(emails, g.Email)
+	}
+	if len(emails) == 0 {
+		log.Printf("no changes to sensitive groups")
+		return nil
+	}
+	approved, err := approvesSensitiveChanges(w, b.String(), emails, approveAll, approval)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		r.held = emails
+	}
+	return nil
+}
+
+// approvesSensitiveChanges prints changes, the changes to the sensitive
+// groups with the given emails, to w and reports whether they are approved,
+// by approveAll or by approval. They are always approved in dry-run mode, as
+// nothing is changed. An approval listing them whose signature doesn't verify
+// is an error.
+func approvesSensitiveChanges(w io.Writer, changes string, emails []string, approveAll bool, approval *sensitiveApproval) (bool, error) {
+	fmt.Fprint(w, changes)
+	switch {
+	case !config.ConfirmChanges:
+		log.Printf("dry-run: %d sensitive groups would change, which needs -approve-sensitive or an -approval-file listing these changes: %s", len(emails), strings.Join(emails, ", "))
+	case approveAll:
+		log.Printf("changes to %d sensitive groups approved with -approve-sensitive: %s", len(emails), strings.Join(emails, ", "))
+	case approval.approves(changes):
+		if err := approval.verify(restrictionsConfig.Sensitive.AllowedSigners); err != nil {
+			return false, err
+		}
+		log.Printf("changes to %d sensitive groups approved in the approval file signed by %s: %s", len(emails), approval.ApprovedBy, strings.Join(emails, ", "))
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// sensitiveChanges returns the diff of the sensitive groups among groups
+// that differ from their configuration, as printed by DiffGroups, and their
+// emails. Existing sensitive groups that aren't configured are included if
+// ReconcileGroups would lock or delete them.
+func (r *Reconciler) sensitiveChanges(groups []GoogleGroup) (string, []string, error) {
+	sensitive := restrictionsConfig.Sensitive
+	live, err := r.adminService.ListGroups()
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to list groups: %w", err)
+	}
+	existing := map[string]*admin.Group{}
+	for _, g := range live.Groups {
+		existing[strings.ToLower(g.Email)] = g
+	}
+
+	var (
+		b      strings.Builder
+		emails []string
+	)
+	for _, want := range groups {
+		ended := want.endedAt(now())
+		want = want.asOf(now())
+		g := takeGroup(existing, want)
+		if !sensitive.isSensitive(want, config.GroupsPath) {
+			continue
+		}
+		// Compare all the settings UpdateGroupSettings sets, not only those
+		// the group lists.
+		want.Settings = reconciledSettings(want.Settings)
+		var have *GoogleGroup
+		if g != nil {
+			h, err := r.existingGroup(g, want)
+			if err != nil {
+				return "", nil, err
+			}
+			h = reconciledState(h, want, ended)
+			have = &h
+		}
+		want := comparableGroup(want)
+		if g != nil && !want.specifies("description") {
+			if _, pending := pendingDeletion(g.Description); pending {
+				// Reconciling a locked group unlocks it, see
+				// adminService.updateGroup.
+				have.Description = g.Description
+				want.Description = withoutPendingDeletion(g.Description)
+			}
+		}
+		differs, err := printGroupDiff(&b, want.EmailId, have, &want)
+		if err != nil {
+			return "", nil, err
+		}
+		if differs {
+			emails = append(emails, want.EmailId)
+		}
+	}
+	if r.skipDeletions {
+		return b.String(), emails, nil
+	}
+
+	var removed []string
+	for email, g := range existing {
+		if sensitive.isSensitiveEmail(g.Email, restrictionsConfig.Restrictions) && !isConfigured(g.Email) {
+			removed = append(removed, email)
+		}
+	}
+	sort.Strings(removed)
+	gracePeriod := config.GetDeletionGracePeriod()
+	for _, email := range removed {
+		g := existing[email]
+		// Groups already locked are left as they are until the end of the
+		// grace period.
+		if after, pending := pendingDeletion(g.Description); pending && gracePeriod != 0 && now().Before(after) {
+			continue
+		}
+		if _, err := printGroupDiff(&b, g.Email, &GoogleGroup{EmailId: g.Email}, nil); err != nil {
+			return "", nil, err
+		}
+		emails = append(emails, g.Email)
+	}
+	return b.String(), emails, nil
+}
+
+// reconciledState returns have, the state of the existing group as returned
+// by existingGroup, without the members ReconcileGroups leaves in place when
+// reconciling it to want, so that only what it changes is compared: the
+// bot-id as an OWNER and, unless want sets ReconcileMembers, the MEMBERs
+// want doesn't list and whose schedule hasn't ended.
+func reconciledState(have, want GoogleGroup, ended []string) GoogleGroup {
+	listed := append(append(append([]string{}, want.Owners...), want.Managers...), want.Members...)
+	if config.BotID != "" && !containsEmail(listed, config.BotID) {
+		var owners []string
+		for _, m := range have.Owners {
+			if !EmailAddressEquals(m, config.BotID) {
+				owners = append(owners, m)
+			}
+		}
+		have.Owners = owners
+	}
+	if want.Settings["ReconcileMembers"] != "true" {
+		var members []string
+		for _, m := range have.Members {
+			if containsEmail(listed, m) || containsEmail(ended, m) {
+				members = append(members, m)
+			}
+		}
+		have.Members = members
+	}
+	return have
+}
+
+// isHeld reports whether the changes to the group with the given email are
+// held by holdSensitiveChanges.
+func (r *Reconciler) isHeld(email string) bool {
+	for _, e := range r.held {
+		if EmailAddressEquals(e, email) {
+			return true
+		}
+	}
+	return false
+}
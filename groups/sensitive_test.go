@@ -0,0 +1,567 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestIsSensitive(t *testing.T) {
+	s := Sensitive{
+		Paths:    []string{"committee-*/groups.yaml"},
+		GroupsRe: []*regexp.Regexp{regexp.MustCompile("^k8s-infra-rbac-.*@kubernetes.io$")},
+	}
+	cases := []struct {
+		desc     string
+		group    GoogleGroup
+		path     string
+		expected bool
+	}{
+		{
+			desc:     "path matches",
+			group:    GoogleGroup{EmailId: "conduct@kubernetes.io"},
+			path:     "/root/committee-code-of-conduct/groups.yaml",
+			expected: true,
+		},
+		{
+			desc:     "email id matches",
+			group:    GoogleGroup{EmailId: "k8s-infra-rbac-foo@kubernetes.io"},
+			path:     "/root/sig-foo/groups.yaml",
+			expected: true,
+		},
+		{
+			desc:     "previous email id matches",
+			group:    GoogleGroup{EmailId: "foo@kubernetes.io", PreviousEmailIds: []string{"k8s-infra-rbac-foo@kubernetes.io"}},
+			path:     "/root/sig-foo/groups.yaml",
+			expected: true,
+		},
+		{
+			desc:  "neither matches",
+			group: GoogleGroup{EmailId: "sig-foo@kubernetes.io"},
+			path:  "/root/sig-foo/groups.yaml",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			c.group.setPath(c.path)
+			if actual := s.isSensitive(c.group, "/root"); actual != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHoldSensitiveChanges(t *testing.T) {
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	stubNow(t, at)
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+		restrictionsConfig = RestrictionsConfig{}
+	}()
+	restrictionsConfig.Sensitive.GroupsRe = []*regexp.Regexp{regexp.MustCompile("^group2@")}
+
+	// group2Settings are the settings of group2 that differ from the
+	// defaults, which are compared with the other settings.
+	group2Settings := map[string]string{"WhoCanModerateMembers": "OWNERS_ONLY"}
+	group1 := GoogleGroup{EmailId: "group1@email.com", Members: []string{"m1-group1@email.com", "new@email.com"}, Managers: []string{"m2-group1@email.com"}}
+	group2 := GoogleGroup{EmailId: "group2@email.com", Members: []string{"m1-group2@email.com", "new@email.com"}, Owners: []string{"m2-group2@email.com"}, Settings: group2Settings}
+	unchangedGroup2 := GoogleGroup{EmailId: "group2@email.com", Members: []string{"m1-group2@email.com"}, Owners: []string{"m2-group2@email.com"}, Settings: group2Settings}
+	// group2State is group2 after its description, as printed by diff.
+	group2State := ` settings:
+     AllowExternalMembers: "true"
+     MembersCanPostAsTheGroup: "false"
+     MessageModerationLevel: MODERATE_NONE
+     WhoCanDiscoverGroup: ALL_IN_DOMAIN_CAN_DISCOVER
+     WhoCanJoin: INVITED_CAN_JOIN
+     WhoCanModerateContent: OWNERS_AND_MANAGERS
+     WhoCanModerateMembers: OWNERS_ONLY
+     WhoCanPostMessage: ALL_MEMBERS_CAN_POST
+     WhoCanViewGroup: ALL_MEMBERS_CAN_VIEW
+     WhoCanViewMembership: ALL_MANAGERS_CAN_VIEW
+ owners:
+     - m2-group2@email.com
+ members:
+     - m1-group2@email.com
+`
+	group2Changes := `--- existing group2@email.com
++++ configured group2@email.com
+ email-id: group2@email.com
+ name: ""
+ description: ""
+` + group2State + "+    - new@email.com\n"
+
+	cases := []struct {
+		desc        string
+		configured  []GoogleGroup
+		description string
+		dryRun      bool
+		approveAll  bool
+		approval    *sensitiveApproval
+		// expectedChanges is what is printed, in addition to group2Changes
+		// if expectedGroup2Changes is set.
+		expectedChanges       string
+		expectedGroup2Changes bool
+		expectedHeld          []string
+	}{
+		{
+			desc:       "no changes to sensitive groups",
+			configured: []GoogleGroup{group1, unchangedGroup2},
+		},
+		{
+			desc:       "members not reconciled",
+			configured: []GoogleGroup{group1, {EmailId: "group2@email.com", Owners: []string{"m2-group2@email.com"}, Settings: map[string]string{"ReconcileMembers": "false", "WhoCanModerateMembers": "OWNERS_ONLY"}}},
+		},
+		{
+			desc:       "members reconciled",
+			configured: []GoogleGroup{group1, {EmailId: "group2@email.com", Owners: []string{"m2-group2@email.com"}, Settings: map[string]string{"ReconcileMembers": "true", "WhoCanModerateMembers": "OWNERS_ONLY"}}},
+			expectedChanges: `--- existing group2@email.com
++++ configured group2@email.com
+ email-id: group2@email.com
+ name: ""
+ description: ""
+ settings:
+     AllowExternalMembers: "true"
+     MembersCanPostAsTheGroup: "false"
+     MessageModerationLevel: MODERATE_NONE
+     ReconcileMembers: "true"
+     WhoCanDiscoverGroup: ALL_IN_DOMAIN_CAN_DISCOVER
+     WhoCanJoin: INVITED_CAN_JOIN
+     WhoCanModerateContent: OWNERS_AND_MANAGERS
+     WhoCanModerateMembers: OWNERS_ONLY
+     WhoCanPostMessage: ALL_MEMBERS_CAN_POST
+     WhoCanViewGroup: ALL_MEMBERS_CAN_VIEW
+     WhoCanViewMembership: ALL_MANAGERS_CAN_VIEW
+ owners:
+     - m2-group2@email.com
+-members:
+-    - m1-group2@email.com
+`,
+			expectedHeld: []string{"group2@email.com"},
+		},
+		{
+			desc:       "setting reset to its default",
+			configured: []GoogleGroup{group1, {EmailId: "group2@email.com", Members: []string{"m1-group2@email.com"}, Owners: []string{"m2-group2@email.com"}}},
+			expectedChanges: `--- existing group2@email.com
++++ configured group2@email.com
+ email-id: group2@email.com
+ name: ""
+ description: ""
+ settings:
+     AllowExternalMembers: "true"
+     MembersCanPostAsTheGroup: "false"
+     MessageModerationLevel: MODERATE_NONE
+     WhoCanDiscoverGroup: ALL_IN_DOMAIN_CAN_DISCOVER
+     WhoCanJoin: INVITED_CAN_JOIN
+     WhoCanModerateContent: OWNERS_AND_MANAGERS
+-    WhoCanModerateMembers: OWNERS_ONLY
++    WhoCanModerateMembers: OWNERS_AND_MANAGERS
+     WhoCanPostMessage: ALL_MEMBERS_CAN_POST
+     WhoCanViewGroup: ALL_MEMBERS_CAN_VIEW
+     WhoCanViewMembership: ALL_MANAGERS_CAN_VIEW
+ owners:
+     - m2-group2@email.com
+ members:
+     - m1-group2@email.com
+`,
+			expectedHeld: []string{"group2@email.com"},
+		},
+		{
+			desc:        "locked group added back",
+			configured:  []GoogleGroup{group1, unchangedGroup2},
+			description: withPendingDeletion("group2", at.Add(time.Hour)),
+			expectedChanges: `--- existing group2@email.com
++++ configured group2@email.com
+ email-id: group2@email.com
+ name: ""
+-description: |-
+-    group2
+-
+-    Pending deletion after 2026-10-19T11:00:00Z, as it was removed from the groups.yaml files.
++description: group2
+` + group2State,
+			expectedHeld: []string{"group2@email.com"},
+		},
+		{
+			desc:                  "dry-run",
+			configured:            []GoogleGroup{group1, group2},
+			dryRun:                true,
+			expectedGroup2Changes: true,
+		},
+		{
+			desc:                  "not approved",
+			configured:            []GoogleGroup{group1, group2},
+			expectedGroup2Changes: true,
+			expectedHeld:          []string{"group2@email.com"},
+		},
+		{
+			desc:                  "approved with -approve-sensitive",
+			configured:            []GoogleGroup{group1, group2},
+			approveAll:            true,
+			expectedGroup2Changes: true,
+		},
+		{
+			desc:                  "approved with an approval file",
+			configured:            []GoogleGroup{group1, group2},
+			approval:              &sensitiveApproval{ApprovedBy: "approver@email.com", Changes: "--- existing other@email.com\n+++ /dev/null\n-email-id: other@email.com\n" + group2Changes},
+			expectedGroup2Changes: true,
+		},
+		{
+			desc:                  "approval file listing other changes",
+			configured:            []GoogleGroup{group1, group2},
+			approval:              &sensitiveApproval{ApprovedBy: "approver@email.com", Changes: strings.Replace(group2Changes, "new@", "other@", 1)},
+			expectedGroup2Changes: true,
+			expectedHeld:          []string{"group2@email.com"},
+		},
+		{
+			desc:            "removed, not approved",
+			configured:      []GoogleGroup{group1},
+			description:     "group2",
+			expectedChanges: "--- existing group2@email.com\n+++ /dev/null\n-email-id: group2@email.com\n-name: \"\"\n-description: \"\"\n",
+			expectedHeld:    []string{"group2@email.com"},
+		},
+		{
+			desc:        "removed and already locked",
+			configured:  []GoogleGroup{group1},
+			description: withPendingDeletion("group2", at.Add(time.Hour)),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			config.ConfirmChanges = !c.dryRun
+			groupsConfig.Groups = c.configured
+			r, fakeAdminClient, _ := newLifecycleReconciler(c.description, memorySnapshotStore{})
+
+			approval := c.approval
+			if approval != nil {
+				approval, restrictionsConfig.Sensitive.AllowedSigners = signedApproval(t, approval.ApprovedBy, approval.Changes)
+			}
+			var out strings.Builder
+			if err := r.holdSensitiveChanges(&out, c.configured, c.approveAll, approval); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectedChanges := c.expectedChanges
+			if c.expectedGroup2Changes {
+				expectedChanges = group2Changes + expectedChanges
+			}
+			if out.String() != expectedChanges {
+				t.Errorf("expected changes:\n%s\ngot:\n%s", expectedChanges, out.String())
+			}
+			if !reflect.DeepEqual(r.held, c.expectedHeld) {
+				t.Errorf("expected held groups %v, got %v", c.expectedHeld, r.held)
+			}
+
+			err := r.ReconcileGroups(c.configured)
+			if len(c.expectedHeld) > 0 {
+				if err == nil || !strings.Contains(err.Error(), "not changing sensitive groups group2@email.com") {
+					t.Errorf("expected an error about the held groups, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.dryRun {
+				return
+			}
+			if _, ok := fakeAdminClient.Members["group1@email.com"]["new@email.com"]; !ok {
+				t.Errorf("expected the changes to group1 to be applied")
+			}
+			group2, ok := fakeAdminClient.Groups["group2@email.com"]
+			if len(c.expectedHeld) > 0 {
+				if !ok || group2.Description != c.description {
+					t.Errorf("expected group2 to be left as it is, got %#v", group2)
+				}
+				if _, added := fakeAdminClient.Members["group2@email.com"]["new@email.com"]; added {
+					t.Errorf("expected the changes to group2 not to be applied")
+				}
+			} else if c.expectedGroup2Changes {
+				if _, added := fakeAdminClient.Members["group2@email.com"]["new@email.com"]; !added {
+					t.Errorf("expected the changes to group2 to be applied")
+				}
+			}
+		})
+	}
+}
+
+// TestHoldRemovedGroupOfSensitivePath removes a group that restrictions only
+// allow in a sensitive path, which has no definition left to tell it is
+// sensitive.
+func TestHoldRemovedGroupOfSensitivePath(t *testing.T) {
+	stubNow(t, time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC))
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		groupsConfig = GroupsConfig{}
+		restrictionsConfig = RestrictionsConfig{}
+	}()
+	restrictionsConfig.Sensitive.Paths = []string{"committee-foo/groups.yaml"}
+	restrictionsConfig.Restrictions = []Restriction{
+		{Path: "committee-foo/groups.yaml", AllowedGroupsRe: []*regexp.Regexp{regexp.MustCompile("^group2@")}},
+		{Path: "sig-foo/groups.yaml", AllowedGroupsRe: []*regexp.Regexp{regexp.MustCompile("^group1@")}},
+	}
+
+	configured := []GoogleGroup{{EmailId: "group1@email.com", Members: []string{"m1-group1@email.com"}, Managers: []string{"m2-group1@email.com"}}}
+	groupsConfig.Groups = configured
+	r, fakeAdminClient, _ := newLifecycleReconciler("group2", memorySnapshotStore{})
+
+	var out strings.Builder
+	if err := r.holdSensitiveChanges(&out, configured, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "--- existing group2@email.com\n+++ /dev/null\n-email-id: group2@email.com\n-name: \"\"\n-description: \"\"\n"
+	if out.String() != expected {
+		t.Errorf("expected changes:\n%s\ngot:\n%s", expected, out.String())
+	}
+	if !reflect.DeepEqual(r.held, []string{"group2@email.com"}) {
+		t.Errorf("expected group2@email.com to be held, got %v", r.held)
+	}
+	if err := r.ReconcileGroups(configured); err == nil || !strings.Contains(err.Error(), "not changing sensitive groups group2@email.com") {
+		t.Errorf("expected an error about the held groups, got %v", err)
+	}
+	if g, ok := fakeAdminClient.Groups["group2@email.com"]; !ok || g.Description != "group2" {
+		t.Errorf("expected group2 to be left as it is, got %#v", g)
+	}
+}
+
+func TestLoadSensitiveApproval(t *testing.T) {
+	cases := []struct {
+		desc        string
+		content     string
+		expected    *sensitiveApproval
+		expectedErr bool
+	}{
+		{
+			desc:     "valid",
+			content:  "approved-by: approver@email.com\nchanges: |\n  --- existing group2@email.com\n  +++ /dev/null\n  -email-id: group2@email.com\n",
+			expected: &sensitiveApproval{ApprovedBy: "approver@email.com", Changes: "--- existing group2@email.com\n+++ /dev/null\n-email-id: group2@email.com\n"},
+		},
+		{
+			desc:        "missing approved-by",
+			content:     "changes: |\n  --- existing group2@email.com\n",
+			expectedErr: true,
+		},
+		{
+			desc:        "missing changes",
+			content:     "approved-by: approver@email.com\n",
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown field",
+			content:     "approved-by: approver@email.com\nchange: foo\n",
+			expectedErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "approval.yaml")
+			if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := loadSensitiveApproval(path)
+			if c.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", c.expectedErr, err)
+			}
+			if c.expected != nil {
+				c.expected.path, c.expected.content = path, []byte(c.content)
+			}
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %#v, got %#v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVerifySensitiveApproval(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	dir := t.TempDir()
+	approverKey := newApprovalKey(t, dir, "approver")
+	otherKey := newApprovalKey(t, dir, "other")
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	writeAllowedSigners(t, allowedSigners, map[string]string{"approver@email.com": approverKey})
+
+	content := "approved-by: approver@email.com\nchanges: |\n  --- existing group2@email.com\n  +++ /dev/null\n  -email-id: group2@email.com\n"
+	cases := []struct {
+		desc           string
+		content        string
+		key            string
+		namespace      string
+		unsigned       bool
+		changed        string
+		allowedSigners string
+		expectedErr    string
+	}{
+		{
+			desc:           "signed by the approver",
+			content:        content,
+			key:            approverKey,
+			namespace:      approvalNamespace,
+			allowedSigners: allowedSigners,
+		},
+		{
+			desc:        "no allowed signers",
+			content:     content,
+			key:         approverKey,
+			namespace:   approvalNamespace,
+			expectedErr: "sets no sensitive allowedSigners",
+		},
+		{
+			desc:           "not signed",
+			content:        content,
+			unsigned:       true,
+			allowedSigners: allowedSigners,
+			expectedErr:    "is not signed by approver@email.com",
+		},
+		{
+			desc:           "signed by someone else",
+			content:        content,
+			key:            otherKey,
+			namespace:      approvalNamespace,
+			allowedSigners: allowedSigners,
+			expectedErr:    "is not signed by approver@email.com",
+		},
+		{
+			desc:           "approved-by someone else than the signer",
+			content:        strings.Replace(content, "approver@", "other@", 1),
+			key:            approverKey,
+			namespace:      approvalNamespace,
+			allowedSigners: allowedSigners,
+			expectedErr:    "is not signed by other@email.com",
+		},
+		{
+			desc:           "signed for another purpose",
+			content:        content,
+			key:            approverKey,
+			namespace:      "git",
+			allowedSigners: allowedSigners,
+			expectedErr:    "is not signed by approver@email.com",
+		},
+		{
+			desc:           "changed after it was signed",
+			content:        content,
+			key:            approverKey,
+			namespace:      approvalNamespace,
+			changed:        strings.Replace(content, "group2@", "group1@", -1),
+			allowedSigners: allowedSigners,
+			expectedErr:    "is not signed by approver@email.com",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "approval.yaml")
+			if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if !c.unsigned {
+				signApproval(t, c.key, c.namespace, path)
+			}
+			if c.changed != "" {
+				if err := os.WriteFile(path, []byte(c.changed), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			approval, err := loadSensitiveApproval(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = approval.verify(c.allowedSigners)
+			if c.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if c.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), c.expectedErr)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+// signedApproval returns the approval of changes by approvedBy, loaded from
+// an approval file signed by their key, and the allowed signers file to
+// verify it with.
+func signedApproval(t *testing.T, approvedBy, changes string) (*sensitiveApproval, string) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	dir := t.TempDir()
+	key := newApprovalKey(t, dir, "approver")
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	writeAllowedSigners(t, allowedSigners, map[string]string{approvedBy: key})
+
+	content, err := yaml.Marshal(sensitiveApproval{ApprovedBy: approvedBy, Changes: changes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "approval.yaml")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	signApproval(t, key, approvalNamespace, path)
+	approval, err := loadSensitiveApproval(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return approval, allowedSigners
+}
+
+// newApprovalKey generates an SSH key without passphrase in dir and returns
+// the path of its private key.
+func newApprovalKey(t *testing.T, dir, name string) string {
+	t.Helper()
+	key := filepath.Join(dir, name)
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("error generating key: %v: %s", err, out)
+	}
+	return key
+}
+
+// writeAllowedSigners writes the allowed signers file at path, allowing the
+// private keys of keys to sign as their principal.
+func writeAllowedSigners(t *testing.T, path string, keys map[string]string) {
+	t.Helper()
+	var b strings.Builder
+	for principal, key := range keys {
+		pub, err := os.ReadFile(key + ".pub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&b, "%s %s", principal, pub)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// signApproval signs the approval file at path with key in namespace, to
+// path + ".sig".
+func signApproval(t *testing.T, key, namespace, path string) {
+	t.Helper()
+	if out, err := exec.Command("ssh-keygen", "-q", "-Y", "sign", "-f", key, "-n", namespace, path).CombinedOutput(); err != nil {
+		t.Fatalf("error signing %s: %v: %s", path, err, out)
+	}
+}
@@ -38,20 +38,41 @@ const (
 	MemberRole  = "MEMBER"
 )
 
+// Delivery settings of a member, see
+// https://developers.google.com/admin-sdk/directory/reference/rest/v1/members
+const (
+	AllMailDelivery  = "ALL_MAIL"
+	DigestDelivery   = "DIGEST"
+	DailyDelivery    = "DAILY"
+	NoneDelivery     = "NONE"
+	DisabledDelivery = "DISABLED"
+)
+
+// UserMemberType is the type of members that are users, as opposed to
+// nested groups (GROUP) or the whole customer (CUSTOMER).
+const UserMemberType = "USER"
+
 // AdminService provides functionality to perform high level
 // tasks using a AdminServiceClient.
 type AdminService interface {
 	AddOrUpdateGroupMembers(group GoogleGroup, role string, members []string) error
 	CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
-	DeleteGroupsIfNecessary() error
 	RemoveOwnerOrManagersFromGroup(group GoogleGroup, members []string) error
 	RemoveMembersFromGroup(group GoogleGroup, members []string) error
+	RemoveGroupMembers(group GoogleGroup, emails []string) error
+	VerifyGroupMembers(group GoogleGroup) ([]StaleMember, error)
 	// ListGroup here is a proxy to the ListGroups method of the underlying
 	// AdminServiceClient being used.
 	ListGroups() (*admin.Groups, error)
 	// ListMembers here is a proxy to the ListMembers method of the underlying
 	// AdminServiceClient being used.
 	ListMembers(groupKey string) ([]*admin.Member, error)
+	// UpdateGroup here is a proxy to the UpdateGroup method of the
+	// underlying AdminServiceClient being used.
+	UpdateGroup(groupKey string, group *admin.Group) (*admin.Group, error)
+	// DeleteGroup here is a proxy to the DeleteGroup method of the
+	// underlying AdminServiceClient being used.
+	DeleteGroup(groupKey string) error
 }
 
 // GroupService provides functionality to perform high level
@@ -61,10 +82,13 @@ type GroupService interface {
 	// Get here is a proxy to the Get method of the
 	// underlying GroupServiceClient
 	Get(groupUniqueID string) (*groupssettings.Groups, error)
+	// Patch here is a proxy to the Patch method of the
+	// underlying GroupServiceClient
+	Patch(groupUniqueID string, settings *groupssettings.Groups) (*groupssettings.Groups, error)
 }
 
-func NewAdminService(ctx context.Context, clientOption option.ClientOption) (AdminService, error) {
-	client, err := NewAdminServiceClient(ctx, clientOption)
+func NewAdminService(ctx context.Context, clientOptions ...option.ClientOption) (AdminService, error) {
+	client, err := NewAdminServiceClient(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -90,8 +114,8 @@ func NewAdminServiceWithClientAndErrFunc(client AdminServiceClient, errFunc clie
 	}, nil
 }
 
-func NewGroupService(ctx context.Context, clientOption option.ClientOption) (GroupService, error) {
-	client, err := NewGroupServiceClient(ctx, clientOption)
+func NewGroupService(ctx context.Context, clientOptions ...option.ClientOption) (GroupService, error) {
+	client, err := NewGroupServiceClient(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -158,12 +182,17 @@ func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string,
 			}
 		}
 
+		delivery := group.DeliveryFor(memberEmailId)
+
 		if member != nil {
 			// update if necessary
-			if member.Role != role {
+			if member.Role != role || delivery != "" && member.DeliverySettings != delivery {
 				member.Role = role
+				if delivery != "" {
+					member.DeliverySettings = delivery
+				}
 				if config.ConfirmChanges {
-					log.Printf("Updating %s to %q as a %s\n", memberEmailId, group.EmailId, role)
+					log.Printf("Updating %s to %q as a %s with delivery %s\n", memberString(member), group.EmailId, role, member.DeliverySettings)
 					_, err := as.client.UpdateMember(group.EmailId, member.Email, member)
 					if err != nil {
 						logErr := fmt.Errorf("unable to update %s in %q as %s: %w", memberEmailId, group.EmailId, role, err)
@@ -171,17 +200,18 @@ func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string,
 						errs = append(errs, logErr)
 						continue
 					}
-					log.Printf("Updated %s to %q as a %s\n", memberEmailId, group.EmailId, role)
+					log.Printf("Updated %s to %q as a %s with delivery %s\n", memberString(member), group.EmailId, role, member.DeliverySettings)
 				} else {
-					log.Printf("dry-run: would update %s to %q as %s\n", memberEmailId, group.EmailId, role)
+					log.Printf("dry-run: would update %s to %q as %s with delivery %s\n", memberString(member), group.EmailId, role, member.DeliverySettings)
 				}
 			}
 			continue
 		}
 
 		member = &admin.Member{
-			Email: memberEmailId,
-			Role:  role,
+			Email:            memberEmailId,
+			Role:             role,
+			DeliverySettings: delivery,
 		}
 
 		// We did not find the person in the google group, so we add them
@@ -214,8 +244,18 @@ func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
 	grp, err := as.client.GetGroup(group.EmailId)
 	if err != nil {
 		if as.checkForAPIErr404(err) {
+			prev, err := as.previousGroup(group)
+			if err != nil {
+				return err
+			}
+			if prev != nil {
+				return as.updateGroup(group, prev)
+			}
 			if !config.ConfirmChanges {
 				log.Printf("dry-run: would create group %q\n", group.EmailId)
+				for _, alias := range group.Aliases {
+					log.Printf("dry-run: would add alias %s to group %q\n", alias, group.EmailId)
+				}
 			} else {
 				log.Printf("Trying to create group: %q\n", group.EmailId)
 				g := admin.Group{
@@ -232,79 +272,146 @@ func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
 					return fmt.Errorf("unable to add new group %q: %w", group.EmailId, err)
 				}
 				log.Printf("> Successfully created group %s\n", g4.Email)
+				return as.reconcileAliases(group, nil)
 			}
 		} else {
 			return fmt.Errorf("unable to fetch group %q: %w", group.EmailId, err)
 		}
 	} else {
-		if group.Name != "" && grp.Name != group.Name ||
-			group.Description != "" && grp.Description != group.Description {
-			if !config.ConfirmChanges {
-				log.Printf("dry-run: would update group name/description %q\n", group.EmailId)
-			} else {
-				log.Printf("Trying to update group: %q\n", group.EmailId)
-				g := admin.Group{
-					Email: group.EmailId,
-				}
-				if group.Name != "" {
-					g.Name = group.Name
-				}
-				if group.Description != "" {
-					g.Description = group.Description
-				}
-				g4, err := as.client.UpdateGroup(group.EmailId, &g)
-				if err != nil {
-					return fmt.Errorf("unable to update group %q: %w", group.EmailId, err)
-				}
-				log.Printf("> Successfully updated group %s\n", g4.Email)
-			}
+		// Groups can be fetched by their aliases.
+		if !group.HasEmail(grp.Email) {
+			return fmt.Errorf("group %q is an alias of group %q, which is not one of its previous email ids", group.EmailId, grp.Email)
 		}
+		return as.updateGroup(group, grp)
 	}
 	return nil
 }
 
-// DeleteGroupsIfNecessary checks against the groups config provided by the user. It
-// first lists all existing groups, if a group in this list does not appear in the
-// provided group config, it will delete this group to match the desired state.
-func (as *adminService) DeleteGroupsIfNecessary() error {
-	g, err := as.client.ListGroups()
-	if err != nil {
-		return fmt.Errorf("unable to retrieve users in domain: %w", err)
-	}
-
-	// aggregate the errors that occurred and return them together in the end.
-	var errs []error
-
-	for _, g := range g.Groups {
-		found := false
-		for _, g2 := range groupsConfig.Groups {
-			if EmailAddressEquals(g2.EmailId, g.Email) {
-				found = true
-				break
+// previousGroup returns the existing group with one of the previous email
+// ids of group, or nil if there is none.
+func (as *adminService) previousGroup(group GoogleGroup) (*admin.Group, error) {
+	for _, id := range group.PreviousEmailIds {
+		grp, err := as.client.GetGroup(id)
+		if err != nil {
+			if as.checkForAPIErr404(err) {
+				continue
 			}
+			return nil, fmt.Errorf("unable to fetch group %q: %w", id, err)
 		}
-		if found {
-			continue
-		}
+		return grp, nil
+	}
+	return nil, nil
+}
 
-		// We did not find the group in our groups.xml, so delete the group
-		if config.ConfirmChanges {
-			log.Printf("Deleting group %s", g.Email)
-			err := as.client.DeleteGroup(g.Email)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("unable to remove group %s : %w", g.Email, err))
-				continue
+// updateGroup updates grp, the existing group, to match group. It is
+// renamed if its email is one of the previous email ids of group.
+func (as *adminService) updateGroup(group GoogleGroup, grp *admin.Group) error {
+	// Start from the existing name and description, so that only those
+	// the group sets are changed, whatever the update semantics.
+	g := admin.Group{
+		Email:       group.EmailId,
+		Name:        grp.Name,
+		Description: grp.Description,
+	}
+	var changed []string
+	renamed := !strings.EqualFold(grp.Email, group.EmailId)
+	if renamed {
+		changed = append(changed, "Email")
+	}
+	if group.specifies("name") && grp.Name != group.Name {
+		g.Name = group.Name
+		changed = append(changed, "Name")
+	}
+	if group.specifies("description") && grp.Description != group.Description {
+		g.Description = group.Description
+		changed = append(changed, "Description")
+	} else if _, pending := pendingDeletion(grp.Description); pending {
+		// The group was locked when it was removed from the config, see
+		// Reconciler.DeleteGroupsIfNecessary. Its settings are unlocked by
+		// reconciling them.
+		g.Description = withoutPendingDeletion(grp.Description)
+		changed = append(changed, "Description")
+	}
+	// Send the changed fields even if empty, to clear them.
+	g.ForceSendFields = changed
+	if len(changed) > 0 {
+		if !config.ConfirmChanges {
+			if renamed {
+				log.Printf("dry-run: would rename group %q to %q\n", grp.Email, group.EmailId)
+			}
+			if !renamed || len(changed) > 1 {
+				log.Printf("dry-run: would update group name/description %q\n", group.EmailId)
 			}
-			log.Printf("Removed group %s\n", g.Email)
 		} else {
-			log.Printf("dry-run: would remove group %s\n", g.Email)
+			log.Printf("Trying to update group: %q\n", grp.Email)
+			g4, err := as.client.UpdateGroup(grp.Email, &g)
+			if err != nil {
+				return fmt.Errorf("unable to update group %q: %w", grp.Email, err)
+			}
+			log.Printf("> Successfully updated group %s\n", g4.Email)
 		}
+	}
 
+	aliases := grp.Aliases
+	if renamed {
+		// Changing the email of a group keeps the previous one as an alias.
+		aliases = append(append([]string{}, aliases...), grp.Email)
 	}
+	return as.reconcileAliases(group, aliases)
+}
 
+// reconcileAliases adds the aliases of group that aren't in existing, the
+// aliases the group has, and removes those that aren't in group.Aliases if
+// the group sets them. Aliases that are previous email ids of the group are
+// kept, so that mail sent to them is still delivered.
+func (as *adminService) reconcileAliases(group GoogleGroup, existing []string) error {
+	if !group.specifies("aliases") {
+		return nil
+	}
+	var errs []error
+	for _, alias := range group.Aliases {
+		if containsAlias(existing, alias) {
+			continue
+		}
+		if !config.ConfirmChanges {
+			log.Printf("dry-run: would add alias %s to group %q\n", alias, group.EmailId)
+			continue
+		}
+		log.Printf("Adding alias %s to group %q\n", alias, group.EmailId)
+		if _, err := as.client.InsertGroupAlias(group.EmailId, alias); err != nil {
+			errs = append(errs, fmt.Errorf("unable to add alias %s to group %q: %w", alias, group.EmailId, err))
+			continue
+		}
+		log.Printf("Added alias %s to group %q\n", alias, group.EmailId)
+	}
+	for _, alias := range existing {
+		if containsAlias(group.Aliases, alias) || containsAlias(group.PreviousEmailIds, alias) {
+			continue
+		}
+		if !config.ConfirmChanges {
+			log.Printf("dry-run: would remove alias %s from group %q\n", alias, group.EmailId)
+			continue
+		}
+		log.Printf("Removing alias %s from group %q\n", alias, group.EmailId)
+		if err := as.client.DeleteGroupAlias(group.EmailId, alias); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove alias %s from group %q: %w", alias, group.EmailId, err))
+			continue
+		}
+		log.Printf("Removed alias %s from group %q\n", alias, group.EmailId)
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
+// containsAlias reports whether aliases has alias, ignoring case.
+func containsAlias(aliases []string, alias string) bool {
+	for _, a := range aliases {
+		if strings.EqualFold(a, alias) {
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveOwnerOrManagersFromGroup lists members of the group and checks against the list of members
 // passed. If a member from the retrieved list of members does not exist in the passed list of members,
 // this member is removed - provided this member had a OWNER/MANAGER role.
@@ -340,10 +447,14 @@ func (as *adminService) RemoveOwnerOrManagersFromGroup(group GoogleGroup, member
 		if found || m.Role == MemberRole {
 			continue
 		}
+		if reason := keepReason(group, m, l, members); reason != "" {
+			log.Printf("not removing %s from %q as a %s: %s\n", m.Email, group.EmailId, m.Role, reason)
+			continue
+		}
 
 		// a person was deleted from a group, let's remove them
 		if config.ConfirmChanges {
-			log.Printf("Removing %s from %q as OWNER or MANAGER\n", m.Email, group.EmailId)
+			log.Printf("Removing %s from %q as OWNER or MANAGER\n", memberString(m), group.EmailId)
 			err := as.client.DeleteMember(group.EmailId, m.Id)
 			if err != nil {
 				logErr := fmt.Errorf("unable to remove %s from %q as a %s: %w", m.Email, group.EmailId, m.Role, err)
@@ -351,15 +462,47 @@ func (as *adminService) RemoveOwnerOrManagersFromGroup(group GoogleGroup, member
 				errs = append(errs, logErr)
 				continue
 			}
-			log.Printf("Removed %s from %q as OWNER or MANAGER\n", m.Email, group.EmailId)
+			log.Printf("Removed %s from %q as OWNER or MANAGER\n", memberString(m), group.EmailId)
 		} else {
-			log.Printf("dry-run: would remove %s from %q as OWNER or MANAGER\n", m.Email, group.EmailId)
+			log.Printf("dry-run: would remove %s from %q as OWNER or MANAGER\n", memberString(m), group.EmailId)
 		}
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
+// keepReason returns why the member m of group, which isn't in the list of
+// members it is reconciled to, must not be removed, or "" if it can be: the
+// bot-id is never removed as an OWNER, as the groups it owns may require it,
+// and neither is the last OWNER of a group that isn't configured with any,
+// as that would leave the group to the domain admins. l are the current
+// members of the group.
+func keepReason(group GoogleGroup, m *admin.Member, l []*admin.Member, members []string) string {
+	if m.Role != OwnerRole {
+		return ""
+	}
+	if config.BotID != "" && EmailAddressEquals(m.Email, config.BotID) {
+		return "it is the bot-id"
+	}
+	if len(group.Owners) > 0 {
+		return ""
+	}
+	for _, other := range l {
+		if other == m || other.Role != OwnerRole {
+			continue
+		}
+		if config.BotID != "" && EmailAddressEquals(other.Email, config.BotID) {
+			return ""
+		}
+		for _, email := range members {
+			if EmailAddressEquals(email, other.Email) {
+				return ""
+			}
+		}
+	}
+	return "it is the last OWNER, add an owner to the group first"
+}
+
 // RemoveMembersFromGroup lists members of the group and checks against the list of members passed.
 // If a member from the retrieved list of members does not exist in the passed list of members, this
 // member is removed. Unlike RemoveOwnerOrManagersFromGroup, RemoveMembersFromGroup will remove the
@@ -391,10 +534,14 @@ func (as *adminService) RemoveMembersFromGroup(group GoogleGroup, members []stri
 		if found {
 			continue
 		}
+		if reason := keepReason(group, m, l, members); reason != "" {
+			log.Printf("not removing %s from %q as a %s: %s\n", m.Email, group.EmailId, m.Role, reason)
+			continue
+		}
 
 		// a person was deleted from a group, let's remove them
 		if config.ConfirmChanges {
-			log.Printf("Removing %s from %q as a %s\n", m.Email, group.EmailId, m.Role)
+			log.Printf("Removing %s from %q as a %s\n", memberString(m), group.EmailId, m.Role)
 			err := as.client.DeleteMember(group.EmailId, m.Id)
 			if err != nil {
 				logErr := fmt.Errorf("unable to remove %s from %q as a %s: %w", m.Email, group.EmailId, m.Role, err)
@@ -402,15 +549,80 @@ func (as *adminService) RemoveMembersFromGroup(group GoogleGroup, members []stri
 				errs = append(errs, logErr)
 				continue
 			}
-			log.Printf("Removed %s from %q as a %s\n", m.Email, group.EmailId, m.Role)
+			log.Printf("Removed %s from %q as a %s\n", memberString(m), group.EmailId, m.Role)
 		} else {
-			log.Printf("dry-run: would remove %s from %q as a %s\n", m.Email, group.EmailId, m.Role)
+			log.Printf("dry-run: would remove %s from %q as a %s\n", memberString(m), group.EmailId, m.Role)
 		}
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
+// RemoveGroupMembers removes the given addresses from the group, whatever
+// their role. Unlike RemoveMembersFromGroup, the other members are kept, so
+// that addresses can be removed from groups that don't reconcile members,
+// e.g. when their schedule ends.
+func (as *adminService) RemoveGroupMembers(group GoogleGroup, emails []string) error {
+	if *verbose {
+		log.Printf("adminService.RemoveGroupMembers %s %v", group.EmailId, emails)
+	}
+	l, err := as.client.ListMembers(group.EmailId)
+	if err != nil {
+		if as.checkForAPIErr404(err) {
+			log.Printf("skipping removing members group %q as group has not yet been created\n", group.EmailId)
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve members in group %q: %w", group.EmailId, err)
+	}
+
+	// kept are the members that remain, which keepReason needs.
+	var kept []string
+	for _, m := range l {
+		if !containsEmail(emails, m.Email) {
+			kept = append(kept, m.Email)
+		}
+	}
+
+	// aggregate the errors that occurred and return them together in the end.
+	var errs []error
+
+	for _, m := range l {
+		if !containsEmail(emails, m.Email) {
+			continue
+		}
+		if reason := keepReason(group, m, l, kept); reason != "" {
+			log.Printf("not removing %s from %q as a %s: %s\n", m.Email, group.EmailId, m.Role, reason)
+			continue
+		}
+
+		if config.ConfirmChanges {
+			log.Printf("Removing %s from %q as a %s\n", memberString(m), group.EmailId, m.Role)
+			err := as.client.DeleteMember(group.EmailId, m.Id)
+			if err != nil {
+				logErr := fmt.Errorf("unable to remove %s from %q as a %s: %w", m.Email, group.EmailId, m.Role, err)
+				log.Printf("%s\n", logErr)
+				errs = append(errs, logErr)
+				continue
+			}
+			log.Printf("Removed %s from %q as a %s\n", memberString(m), group.EmailId, m.Role)
+		} else {
+			log.Printf("dry-run: would remove %s from %q as a %s\n", memberString(m), group.EmailId, m.Role)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// containsEmail reports whether emails has an address equal to email.
+func containsEmail(emails []string, email string) bool {
+	for _, e := range emails {
+		if EmailAddressEquals(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
 // ListGroups lists all the groups available.
 func (as *adminService) ListGroups() (*admin.Groups, error) {
 	return as.client.ListGroups()
@@ -421,6 +633,16 @@ func (as *adminService) ListMembers(groupKey string) ([]*admin.Member, error) {
 	return as.client.ListMembers(groupKey)
 }
 
+// UpdateGroup updates the group with a particular groupKey.
+func (as *adminService) UpdateGroup(groupKey string, group *admin.Group) (*admin.Group, error) {
+	return as.client.UpdateGroup(groupKey, group)
+}
+
+// DeleteGroup deletes the group with a particular groupKey.
+func (as *adminService) DeleteGroup(groupKey string) error {
+	return as.client.DeleteGroup(groupKey)
+}
+
 var _ AdminService = (*adminService)(nil)
 
 type groupService struct {
@@ -453,40 +675,10 @@ func (gs *groupService) UpdateGroupSettings(group GoogleGroup) error {
 	deepCopySettings(&g2, &haveSettings)
 	deepCopySettings(&haveSettings, &wantSettings)
 
-	// This sets safe/sane defaults
-	wantSettings.AllowExternalMembers = "true"
-	wantSettings.WhoCanJoin = "INVITED_CAN_JOIN"
-	wantSettings.WhoCanViewMembership = "ALL_MANAGERS_CAN_VIEW"
-	wantSettings.WhoCanViewGroup = "ALL_MEMBERS_CAN_VIEW"
-	wantSettings.WhoCanDiscoverGroup = "ALL_IN_DOMAIN_CAN_DISCOVER"
-	wantSettings.WhoCanModerateMembers = "OWNERS_AND_MANAGERS"
-	wantSettings.WhoCanModerateContent = "OWNERS_AND_MANAGERS"
-	wantSettings.WhoCanPostMessage = "ALL_MEMBERS_CAN_POST"
-	wantSettings.MessageModerationLevel = "MODERATE_NONE"
-	wantSettings.MembersCanPostAsTheGroup = "false"
-
-	for key, value := range group.Settings {
-		switch key {
-		case "AllowExternalMembers":
-			wantSettings.AllowExternalMembers = value
-		case "AllowWebPosting":
-			wantSettings.AllowWebPosting = value
-		case "WhoCanJoin":
-			wantSettings.WhoCanJoin = value
-		case "WhoCanViewMembership":
-			wantSettings.WhoCanViewMembership = value
-		case "WhoCanViewGroup":
-			wantSettings.WhoCanViewGroup = value
-		case "WhoCanDiscoverGroup":
-			wantSettings.WhoCanDiscoverGroup = value
-		case "WhoCanModerateMembers":
-			wantSettings.WhoCanModerateMembers = value
-		case "WhoCanPostMessage":
-			wantSettings.WhoCanPostMessage = value
-		case "MessageModerationLevel":
-			wantSettings.MessageModerationLevel = value
-		case "MembersCanPostAsTheGroup":
-			wantSettings.MembersCanPostAsTheGroup = value
+	v := reflect.ValueOf(&wantSettings).Elem()
+	for key, value := range reconciledSettings(group.Settings) {
+		if f := v.FieldByName(key); f.IsValid() && f.Kind() == reflect.String {
+			f.SetString(value)
 		}
 	}
 
@@ -512,8 +704,60 @@ func (gs *groupService) Get(groupUniqueID string) (*groupssettings.Groups, error
 	return gs.client.Get(groupUniqueID)
 }
 
+func (gs *groupService) Patch(groupUniqueID string, settings *groupssettings.Groups) (*groupssettings.Groups, error) {
+	return gs.client.Patch(groupUniqueID, settings)
+}
+
 var _ GroupService = (*groupService)(nil)
 
+// defaultSettings are safe/sane defaults of the settings of every group,
+// which UpdateGroupSettings applies unless the group sets them.
+var defaultSettings = map[string]string{
+	"AllowExternalMembers":     "true",
+	"WhoCanJoin":               "INVITED_CAN_JOIN",
+	"WhoCanViewMembership":     "ALL_MANAGERS_CAN_VIEW",
+	"WhoCanViewGroup":          "ALL_MEMBERS_CAN_VIEW",
+	"WhoCanDiscoverGroup":      "ALL_IN_DOMAIN_CAN_DISCOVER",
+	"WhoCanModerateMembers":    "OWNERS_AND_MANAGERS",
+	"WhoCanModerateContent":    "OWNERS_AND_MANAGERS",
+	"WhoCanPostMessage":        "ALL_MEMBERS_CAN_POST",
+	"MessageModerationLevel":   "MODERATE_NONE",
+	"MembersCanPostAsTheGroup": "false",
+}
+
+// appliedSettings are the settings of a group that UpdateGroupSettings
+// applies when the group sets them.
+var appliedSettings = map[string]bool{
+	"AllowExternalMembers":     true,
+	"AllowWebPosting":          true,
+	"WhoCanJoin":               true,
+	"WhoCanViewMembership":     true,
+	"WhoCanViewGroup":          true,
+	"WhoCanDiscoverGroup":      true,
+	"WhoCanModerateMembers":    true,
+	"WhoCanPostMessage":        true,
+	"MessageModerationLevel":   true,
+	"MembersCanPostAsTheGroup": true,
+}
+
+// reconciledSettings returns the settings UpdateGroupSettings gives a group
+// setting settings: defaultSettings, overridden by those of settings it
+// applies. Settings that configure the reconciler rather than the group,
+// such as ReconcileMembers, are kept.
+func reconciledSettings(settings map[string]string) map[string]string {
+	res := map[string]string{}
+	for key, value := range defaultSettings {
+		res[key] = value
+	}
+	groupSettings := reflect.TypeOf(groupssettings.Groups{})
+	for key, value := range settings {
+		if _, ok := groupSettings.FieldByName(key); appliedSettings[key] || !ok {
+			res[key] = value
+		}
+	}
+	return res
+}
+
 // DeepCopy deepcopies a to b using json marshaling. This discards fields like
 // the server response that don't have a specifc json field name.
 func deepCopySettings(a, b interface{}) {
@@ -521,25 +765,17 @@ func deepCopySettings(a, b interface{}) {
 	json.Unmarshal(byt, b)
 }
 
-// EmailAddressEquals checks equivalence between two e-mail addresses according
-// to the following rules:
-// - email addresses are case-insensitive (e.g. FOO@bar.com == foo@bar.com)
-// - local parts are dot-inensitive (e.g. foo@bar.com == f.o.o@bar.com)
+// EmailAddressEquals checks equivalence between two e-mail addresses, which
+// are equal if they have the same CanonicalEmail.
 func EmailAddressEquals(a, b string) bool {
-	// if they match case-insensitive, don't bother checking dot-insensitive
-	if strings.EqualFold(a, b) {
-		return true
-	}
-	aParts := strings.Split(a, "@")
-	bParts := strings.Split(b, "@")
-	// These aren't valid e-mail addresses if they don't have exactly one @
-	// and we already know they're not case-insensitively equal, so return false
-	if len(aParts) != 2 || len(bParts) != 2 {
-		return false
-	}
-	// strip dots from local parts and case-insensitively compare the resulting
-	// email addresses
-	aLocal := strings.ReplaceAll(aParts[0], ".", "")
-	bLocal := strings.ReplaceAll(bParts[0], ".", "")
-	return strings.EqualFold(aLocal+"@"+aParts[1], bLocal+"@"+bParts[1])
+	return CanonicalEmail(a) == CanonicalEmail(b)
+}
+
+// memberString describes a member by its email, followed by its type if it
+// is not a user, so that nested groups stand out in logs.
+func memberString(m *admin.Member) string {
+	if m.Type == "" || m.Type == UserMemberType {
+		return m.Email
+	}
+	return fmt.Sprintf("%s (%s)", m.Email, m.Type)
 }
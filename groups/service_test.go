@@ -22,6 +22,7 @@ import (
 
 	admin "google.golang.org/api/admin/directory/v1"
 	groupssettings "google.golang.org/api/groupssettings/v1"
+	"gopkg.in/yaml.v3"
 	"k8s.io/k8s.io/groups/fake"
 )
 
@@ -48,11 +49,23 @@ func checkForMemberListEquality(a, b []*admin.Member) bool {
 		if inB.Role != aMember.Role {
 			return false
 		}
+		if deliveryOrDefault(inB) != deliveryOrDefault(aMember) {
+			return false
+		}
 	}
 
 	return true
 }
 
+// deliveryOrDefault returns the delivery setting of m, which defaults to
+// ALL_MAIL when unset.
+func deliveryOrDefault(m *admin.Member) string {
+	if m.DeliverySettings == "" {
+		return AllMailDelivery
+	}
+	return m.DeliverySettings
+}
+
 // This checks for equality of two group lists based on three things:
 // 1. Email ID
 // 2. Name
@@ -77,11 +90,27 @@ func checkForGroupListEquality(a, b []*admin.Group) bool {
 		if inB.Name != aGroup.Name || inB.Description != aGroup.Description {
 			return false
 		}
+		if len(inB.Aliases) != 0 || len(aGroup.Aliases) != 0 {
+			if !reflect.DeepEqual(inB.Aliases, aGroup.Aliases) {
+				return false
+			}
+		}
 	}
 
 	return true
 }
 
+// groupFromYAML decodes a GoogleGroup from its YAML, so that it records the
+// fields it sets.
+func groupFromYAML(t *testing.T, content string) GoogleGroup {
+	t.Helper()
+	var g GoogleGroup
+	if err := yaml.Unmarshal([]byte(content), &g); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
 // This checks for equality of one admin.Group list and one GoogleGroup list based on 3 things:
 // 1. Email ID
 // 2. Name
@@ -132,9 +161,11 @@ func getGroupListInPrintableForm(groups []*admin.Group) []admin.Group {
 func TestAddOrUpdateGroupMembers(t *testing.T) {
 	config.ConfirmChanges = true
 	cases := []struct {
-		desc            string
-		g               GoogleGroup
-		members         []string
+		desc    string
+		g       GoogleGroup
+		members []string
+		// current sets the delivery of existing members before the update.
+		current         map[string]string
 		expectedMembers []*admin.Member
 		role            string
 	}{
@@ -169,6 +200,57 @@ func TestAddOrUpdateGroupMembers(t *testing.T) {
 			},
 			role: OwnerRole,
 		},
+		{
+			desc: "change member delivery, update operation",
+			g: GoogleGroup{
+				EmailId:  "group1@email.com",
+				Delivery: map[string]string{"m1-group1@email.com": DigestDelivery},
+			},
+			members: []string{"m1-group1@email.com"},
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group1@email.com", Role: MemberRole, DeliverySettings: DigestDelivery},
+				{Email: "m2-group1@email.com", Role: ManagerRole},
+			},
+			role: MemberRole,
+		},
+		{
+			desc: "change member role and delivery, update operation",
+			g: GoogleGroup{
+				EmailId:  "group1@email.com",
+				Delivery: map[string]string{"M1-Group1@email.com": NoneDelivery},
+			},
+			members: []string{"m1-group1@email.com"},
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group1@email.com", Role: ManagerRole, DeliverySettings: NoneDelivery},
+				{Email: "m2-group1@email.com", Role: ManagerRole},
+			},
+			role: ManagerRole,
+		},
+		{
+			desc: "new member with delivery, create operation",
+			g: GoogleGroup{
+				EmailId:  "group1@email.com",
+				Delivery: map[string]string{"new-group1@email.com": DisabledDelivery},
+			},
+			members: []string{"new-group1@email.com"},
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group1@email.com", Role: MemberRole},
+				{Email: "m2-group1@email.com", Role: ManagerRole},
+				{Email: "new-group1@email.com", Role: MemberRole, DeliverySettings: DisabledDelivery},
+			},
+			role: MemberRole,
+		},
+		{
+			desc:    "member without delivery keeps its current delivery",
+			g:       GoogleGroup{EmailId: "group1@email.com"},
+			members: []string{"m1-group1@email.com"},
+			current: map[string]string{"m1-group1@email.com": DailyDelivery},
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group1@email.com", Role: MemberRole, DeliverySettings: DailyDelivery},
+				{Email: "m2-group1@email.com", Role: ManagerRole},
+			},
+			role: MemberRole,
+		},
 	}
 
 	errFunc := func(err error) bool {
@@ -176,6 +258,9 @@ func TestAddOrUpdateGroupMembers(t *testing.T) {
 	}
 	for _, c := range cases {
 		fakeClient := fake.NewAugmentedFakeAdminServiceClient()
+		for email, delivery := range c.current {
+			fakeClient.Members[c.g.EmailId][email].DeliverySettings = delivery
+		}
 
 		adminSvc, err := NewAdminServiceWithClientAndErrFunc(fakeClient, errFunc)
 		if err != nil {
@@ -240,6 +325,39 @@ func TestCreateOrUpdateGroupIfNescessary(t *testing.T) {
 				{Email: "group2@email.com", Name: "group2", Description: "group2"},
 			},
 		},
+		{
+			desc: "group exists, but group description was cleared, update group",
+			g:    groupFromYAML(t, "email-id: group1@email.com\nname: group1\ndescription: \"\"\n"),
+			expectedGroups: []*admin.Group{
+				{Email: "group1@email.com", Name: "group1", Description: ""},
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+			},
+		},
+		{
+			desc: "group exists, name and description not set, do nothing",
+			g:    groupFromYAML(t, "email-id: group1@email.com\n"),
+			expectedGroups: []*admin.Group{
+				{Email: "group1@email.com", Name: "group1", Description: "group1"},
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+			},
+		},
+		{
+			desc: "group exists, but alias was added, add alias",
+			g:    GoogleGroup{EmailId: "group1@email.com", Aliases: []string{"alias1@email.com"}},
+			expectedGroups: []*admin.Group{
+				{Email: "group1@email.com", Name: "group1", Description: "group1", Aliases: []string{"alias1@email.com"}},
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+			},
+		},
+		{
+			desc: "group does not exist, add group and aliases",
+			g:    GoogleGroup{EmailId: "group3@email.com", Name: "group3", Description: "group3", Aliases: []string{"alias3@email.com"}},
+			expectedGroups: []*admin.Group{
+				{Email: "group1@email.com", Name: "group1", Description: "group1"},
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+				{Email: "group3@email.com", Name: "group3", Description: "group3", Aliases: []string{"alias3@email.com"}},
+			},
+		},
 	}
 
 	errFunc := func(err error) bool {
@@ -273,24 +391,101 @@ func TestCreateOrUpdateGroupIfNescessary(t *testing.T) {
 	}
 }
 
-func TestDeleteGroupsIfNecessary(t *testing.T) {
+func TestReconcileAliases(t *testing.T) {
 	config.ConfirmChanges = true
 	cases := []struct {
-		desc         string
-		desiredState []GoogleGroup
+		desc            string
+		g               GoogleGroup
+		expectedAliases []string
 	}{
 		{
-			desc: "states match, nothing to reconcile",
-			desiredState: []GoogleGroup{
-				{EmailId: "group1@email.com", Name: "group1", Description: "group1"},
-				{EmailId: "group2@email.com", Name: "group2", Description: "group2"},
+			desc:            "aliases not set, keep existing aliases",
+			g:               groupFromYAML(t, "email-id: group1@email.com\n"),
+			expectedAliases: []string{"old@email.com", "kept@email.com"},
+		},
+		{
+			desc:            "aliases set, add and remove aliases",
+			g:               groupFromYAML(t, "email-id: group1@email.com\naliases:\n  - Kept@email.com\n  - new@email.com\n"),
+			expectedAliases: []string{"kept@email.com", "new@email.com"},
+		},
+		{
+			desc: "aliases set to an empty list, remove all aliases",
+			g:    groupFromYAML(t, "email-id: group1@email.com\naliases: []\n"),
+		},
+	}
+
+	errFunc := func(err error) bool {
+		return err != nil
+	}
+	for _, c := range cases {
+		fakeClient := fake.NewAugmentedFakeAdminServiceClient()
+		fakeClient.Groups["group1@email.com"].Aliases = []string{"old@email.com", "kept@email.com"}
+
+		adminSvc, err := NewAdminServiceWithClientAndErrFunc(fakeClient, errFunc)
+		if err != nil {
+			t.Errorf("error creating client %v", err)
+		}
+
+		if err := adminSvc.CreateOrUpdateGroupIfNescessary(c.g); err != nil {
+			t.Errorf("error while executing CreateOrUpdateGroupIfNescessary for case %s: %v", c.desc, err)
+		}
+
+		g, err := fakeClient.GetGroup("group1@email.com")
+		if err != nil {
+			t.Fatalf("error while getting group for case %s: %v", c.desc, err)
+		}
+		if len(g.Aliases) != 0 || len(c.expectedAliases) != 0 {
+			if !reflect.DeepEqual(c.expectedAliases, g.Aliases) {
+				t.Errorf("unexpected aliases for %s, expected: %v, got: %v", c.desc, c.expectedAliases, g.Aliases)
+			}
+		}
+		if g.Name != "group1" || g.Description != "group1" {
+			t.Errorf("unexpected name and description for %s: %q, %q", c.desc, g.Name, g.Description)
+		}
+	}
+}
+
+func TestRenameGroup(t *testing.T) {
+	config.ConfirmChanges = true
+	cases := []struct {
+		desc           string
+		g              GoogleGroup
+		expectedGroups []*admin.Group
+		expectedErr    bool
+	}{
+		{
+			desc: "group exists with a previous email id, rename it and keep the previous email id as an alias",
+			g:    GoogleGroup{EmailId: "group3@email.com", PreviousEmailIds: []string{"group0@email.com", "group1@email.com"}, Description: "group3"},
+			expectedGroups: []*admin.Group{
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+				{Email: "group3@email.com", Name: "group1", Description: "group3", Aliases: []string{"group1@email.com"}},
+			},
+		},
+		{
+			desc: "group was renamed, keep the previous email id as an alias even if aliases are set",
+			g:    GoogleGroup{EmailId: "group3@email.com", PreviousEmailIds: []string{"group1@email.com"}, Aliases: []string{}},
+			expectedGroups: []*admin.Group{
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+				{Email: "group3@email.com", Name: "group1", Description: "group1", Aliases: []string{"group1@email.com"}},
+			},
+		},
+		{
+			desc: "no group with a previous email id, create group",
+			g:    GoogleGroup{EmailId: "group3@email.com", PreviousEmailIds: []string{"group0@email.com"}, Name: "group3", Description: "group3"},
+			expectedGroups: []*admin.Group{
+				{Email: "group1@email.com", Name: "group1", Description: "group1"},
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
+				{Email: "group3@email.com", Name: "group3", Description: "group3"},
 			},
 		},
 		{
-			desc: "mismatch in desired state, delete group2",
-			desiredState: []GoogleGroup{
-				{EmailId: "group1@email.com", Name: "group1", Description: "group1"},
+			desc: "email id is an alias of another group, fail",
+			g:    GoogleGroup{EmailId: "alias@email.com"},
+			expectedGroups: []*admin.Group{
+				{Email: "group1@email.com", Name: "group1", Description: "group1", Aliases: []string{"alias@email.com"}},
+				{Email: "group2@email.com", Name: "group2", Description: "group2"},
 			},
+			expectedErr: true,
 		},
 	}
 
@@ -299,30 +494,34 @@ func TestDeleteGroupsIfNecessary(t *testing.T) {
 	}
 	for _, c := range cases {
 		fakeClient := fake.NewAugmentedFakeAdminServiceClient()
+		if c.expectedErr {
+			fakeClient.Groups["group1@email.com"].Aliases = []string{"alias@email.com"}
+		}
 
 		adminSvc, err := NewAdminServiceWithClientAndErrFunc(fakeClient, errFunc)
 		if err != nil {
 			t.Errorf("error creating client %v", err)
 		}
-		groupsConfig.Groups = c.desiredState
 
-		err = adminSvc.DeleteGroupsIfNecessary()
-		if err != nil {
-			t.Errorf("error while executing DeleteGroupsIfNecessary for case %s: %s", c.desc, err.Error())
+		err = adminSvc.CreateOrUpdateGroupIfNescessary(c.g)
+		if c.expectedErr != (err != nil) {
+			t.Errorf("unexpected error for case %s, expected an error: %v, got: %v", c.desc, c.expectedErr, err)
 		}
 
 		result, err := fakeClient.ListGroups()
 		if err != nil {
 			t.Errorf("error while listing groups for case %s: %v", c.desc, err)
 		}
-
-		if !checkForAdminGroupGoogleGroupEquality(result.Groups, c.desiredState) {
+		if !checkForGroupListEquality(result.Groups, c.expectedGroups) {
 			t.Errorf("unexpected list of groups for %s, expected: %#v, got: %#v",
 				c.desc,
-				c.desiredState,
+				getGroupListInPrintableForm(c.expectedGroups),
 				getGroupListInPrintableForm(result.Groups),
 			)
 		}
+		if _, ok := fakeClient.Members[c.expectedGroups[len(c.expectedGroups)-1].Email]; !ok {
+			t.Errorf("expected the members of %s to be kept for case %s", c.expectedGroups[len(c.expectedGroups)-1].Email, c.desc)
+		}
 	}
 }
 
@@ -332,7 +531,9 @@ func TestRemoveOwnerOrManagersFromGroup(t *testing.T) {
 		desc            string
 		g               GoogleGroup
 		desiredState    []string
+		botID           string
 		expectedMembers []*admin.Member
+		expectedErr     bool
 	}{
 		{
 			desc:         "state matches, no deletion",
@@ -360,7 +561,36 @@ func TestRemoveOwnerOrManagersFromGroup(t *testing.T) {
 				{Email: "m1-group1@email.com", Role: MemberRole},
 			},
 		},
+		{
+			desc:         "member to delete is the last OWNER, skip deletion",
+			g:            GoogleGroup{EmailId: "group2@email.com"},
+			desiredState: []string{},
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group2@email.com", Role: MemberRole},
+				{Email: "m2-group2@email.com", Role: OwnerRole},
+			},
+		},
+		{
+			desc:         "member to delete is the bot-id as an OWNER, skip deletion",
+			g:            GoogleGroup{EmailId: "group2@email.com", Owners: []string{"new-owner@email.com"}},
+			desiredState: []string{"new-owner@email.com"},
+			botID:        "m2-group2@email.com",
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group2@email.com", Role: MemberRole},
+				{Email: "m2-group2@email.com", Role: OwnerRole},
+			},
+		},
+		{
+			desc:         "member to delete is the bot-id as a MANAGER, perform deletion",
+			g:            GoogleGroup{EmailId: "group1@email.com"},
+			desiredState: []string{"m1-group1@email.com"},
+			botID:        "m2-group1@email.com",
+			expectedMembers: []*admin.Member{
+				{Email: "m1-group1@email.com", Role: MemberRole},
+			},
+		},
 	}
+	defer func() { config.BotID = "" }()
 
 	errFunc := func(err error) bool {
 		return err != nil
@@ -373,9 +603,10 @@ func TestRemoveOwnerOrManagersFromGroup(t *testing.T) {
 			t.Errorf("error creating client %v", err)
 		}
 
+		config.BotID = c.botID
 		err = adminSvc.RemoveOwnerOrManagersFromGroup(c.g, c.desiredState)
-		if err != nil {
-			t.Errorf("error while executing RemoveOwnerOrManagersFromGroup for case %s: %s", c.desc, err.Error())
+		if c.expectedErr != (err != nil) {
+			t.Errorf("expected error %v while executing RemoveOwnerOrManagersFromGroup for case %s, got: %v", c.expectedErr, c.desc, err)
 		}
 
 		result, err := fakeClient.ListMembers(c.g.EmailId)
@@ -609,21 +840,45 @@ func TestEmailAddressEquals(t *testing.T) {
 			expected: true,
 		},
 		{
-			name:     "equal dot-insensitive",
+			name:     "local part is dot-sensitive for other domains",
 			a:        "foo@bar.com",
 			b:        "f.o.o@bar.com",
+			expected: false,
+		},
+		{
+			name:     "equal dot-insensitive for gmail",
+			a:        "foo@gmail.com",
+			b:        "f.o.o@gmail.com",
+			expected: true,
+		},
+		{
+			name:     "equal case-and-dot-insensitive for gmail",
+			a:        "foo@gmail.com",
+			b:        "F.O.O@gmail.com",
 			expected: true,
 		},
 		{
-			name:     "equal case-and-dot-insensitive",
+			name:     "equal plus-insensitive for gmail",
+			a:        "foo@gmail.com",
+			b:        "foo+k8s@gmail.com",
+			expected: true,
+		},
+		{
+			name:     "local part is plus-sensitive for other domains",
 			a:        "foo@bar.com",
-			b:        "F.O.O@bar.com",
+			b:        "foo+k8s@bar.com",
+			expected: false,
+		},
+		{
+			name:     "googlemail is an alias of gmail",
+			a:        "f.oo@googlemail.com",
+			b:        "foo@gmail.com",
 			expected: true,
 		},
 		{
 			name:     "host is dot-sensitive",
 			a:        "foo@bar.com",
-			b:        "f.o.o@b.a.r.com",
+			b:        "foo@b.a.r.com",
 			expected: false,
 		},
 		{
@@ -632,6 +887,12 @@ func TestEmailAddressEquals(t *testing.T) {
 			b:        "foo@BAR.com",
 			expected: true,
 		},
+		{
+			name:     "invalid addresses are case-insensitive",
+			a:        "foo",
+			b:        "FOO",
+			expected: true,
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// gcsScheme prefixes snapshot paths that are Cloud Storage locations.
+const gcsScheme = "gs://"
+
+// snapshotStore saves the snapshots of groups before they are deleted.
+type snapshotStore interface {
+	// Save saves content as the snapshot with the given name, a relative
+	// slash-separated path.
+	Save(name string, content []byte) error
+	// String describes where snapshots are saved, for logging.
+	String() string
+}
+
+// newSnapshotStore returns the store saving snapshots to snapshotPath, see
+// Tenant.SnapshotPath, or nil if snapshotPath is empty. Cloud Storage is
+// accessed with clientOptions, by default with Application Default
+// Credentials rather than those used for the groups, which are delegated
+// for the Workspace APIs only.
+func newSnapshotStore(ctx context.Context, snapshotPath string, clientOptions ...option.ClientOption) (snapshotStore, error) {
+	if snapshotPath == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(snapshotPath, gcsScheme) {
+		return dirSnapshotStore(snapshotPath), nil
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(snapshotPath, gcsScheme), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid snapshot-path %s: missing bucket", snapshotPath)
+	}
+	return &gcsSnapshotStore{ctx: ctx, bucket: bucket, prefix: prefix, clientOptions: clientOptions}, nil
+}
+
+// dirSnapshotStore saves snapshots as files under a local directory.
+type dirSnapshotStore string
+
+func (d dirSnapshotStore) Save(name string, content []byte) error {
+	p := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, content, 0644)
+}
+
+func (d dirSnapshotStore) String() string {
+	return string(d)
+}
+
+// gcsSnapshotStore saves snapshots as objects of a Cloud Storage bucket,
+// with names starting with prefix. Its client is only created when saving
+// the first snapshot, so that dry-runs don't need credentials for it.
+type gcsSnapshotStore struct {
+	ctx           context.Context
+	bucket        string
+	prefix        string
+	clientOptions []option.ClientOption
+
+	mutex   sync.Mutex
+	service *storage.Service
+}
+
+func (s *gcsSnapshotStore) Save(name string, content []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.service == nil {
+		service, err := storage.NewService(s.ctx, s.clientOptions...)
+		if err != nil {
+			return fmt.Errorf("unable to create Cloud Storage client: %w", err)
+		}
+		s.service = service
+	}
+
+	object := &storage.Object{
+		Name:        path.Join(s.prefix, name),
+		ContentType: "application/json",
+	}
+	_, err := s.service.Objects.Insert(s.bucket, object).Media(bytes.NewReader(content)).Context(s.ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to save %s: %w", s.url(object.Name), err)
+	}
+	return nil
+}
+
+func (s *gcsSnapshotStore) String() string {
+	return s.url(s.prefix)
+}
+
+// url returns the gs:// URL of the object with the given name.
+func (s *gcsSnapshotStore) url(name string) string {
+	return gcsScheme + path.Join(s.bucket, name)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestDirSnapshotStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := newSnapshotStore(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("group@example.com/20261019T100000Z.json", []byte("{}")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "group@example.com", "20261019T100000Z.json"))
+	if err != nil || string(content) != "{}" {
+		t.Errorf("expected the snapshot to be saved, got %q, %v", content, err)
+	}
+}
+
+func TestGCSSnapshotStore(t *testing.T) {
+	var (
+		path, query, body string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"name": "groups/group@example.com/20261019T100000Z.json"}`)
+	}))
+	defer server.Close()
+
+	store, err := newSnapshotStore(context.Background(), "gs://bucket/groups", option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := store.String(); s != "gs://bucket/groups" {
+		t.Errorf("unexpected store %s", s)
+	}
+	if err := store.Save("group@example.com/20261019T100000Z.json", []byte(`{"snapshot": true}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/upload/storage/v1/b/bucket/o" {
+		t.Errorf("unexpected upload path %s", path)
+	}
+	if !strings.Contains(query, "uploadType=multipart") {
+		t.Errorf("unexpected upload query %s", query)
+	}
+	for _, s := range []string{`"name":"groups/group@example.com/20261019T100000Z.json"`, `{"snapshot": true}`} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected upload to contain %s, got %s", s, body)
+		}
+	}
+
+	if _, err := newSnapshotStore(context.Background(), "gs://"); err == nil {
+		t.Errorf("expected an error for a snapshot-path without bucket")
+	}
+	if store, err := newSnapshotStore(context.Background(), ""); store != nil || err != nil {
+		t.Errorf("expected no store without snapshot-path, got %v, %v", store, err)
+	}
+}
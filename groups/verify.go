@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// ActiveMemberStatus is the status of members that receive mail.
+	ActiveMemberStatus = "ACTIVE"
+	// GroupMemberType is the type of members that are nested groups.
+	GroupMemberType = "GROUP"
+)
+
+// StaleMember is an owner, manager or member listed in groups.yaml that
+// should likely be cleaned up.
+type StaleMember struct {
+	Group  string
+	Email  string
+	Reason string
+}
+
+func (s StaleMember) String() string {
+	return fmt.Sprintf("%s: %s: %s", s.Group, s.Email, s.Reason)
+}
+
+// VerifyGroupMembers checks the owners, managers and members of group:
+//   - members whose membership status is not ACTIVE are stale,
+//   - members that are groups must exist,
+//   - members in the domain of the tenant (or of the group, if the tenant
+//     has no domain) must be existing users that are not suspended.
+//
+// Addresses outside of the domain can't be checked and are skipped, unless
+// their membership isn't ACTIVE.
+func (as *adminService) VerifyGroupMembers(group GoogleGroup) ([]StaleMember, error) {
+	current := map[string]*admin.Member{}
+	members, err := as.client.ListMembers(group.EmailId)
+	if err != nil && !as.checkForAPIErr404(err) {
+		return nil, fmt.Errorf("unable to retrieve members in group %q: %w", group.EmailId, err)
+	}
+	for _, m := range members {
+		current[CanonicalEmail(m.Email)] = m
+	}
+
+	var (
+		stale []StaleMember
+		errs  []error
+	)
+	emails := append(append(append([]string{}, group.Owners...), group.Managers...), group.Members...)
+	for _, email := range emails {
+		m := current[CanonicalEmail(email)]
+		if m != nil && m.Status != "" && m.Status != ActiveMemberStatus {
+			stale = append(stale, StaleMember{Group: group.EmailId, Email: email, Reason: fmt.Sprintf("membership status is %s", m.Status)})
+			continue
+		}
+
+		var reason string
+		switch {
+		case m != nil && m.Type == GroupMemberType:
+			reason, err = as.verifyGroup(email)
+		case inDomain(email, group):
+			reason, err = as.verifyUser(email, m == nil)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to verify %s in %q: %w", email, group.EmailId, err))
+			continue
+		}
+		if reason != "" {
+			stale = append(stale, StaleMember{Group: group.EmailId, Email: email, Reason: reason})
+		}
+	}
+	return stale, utilerrors.NewAggregate(errs)
+}
+
+// verifyGroup returns why the group with the given email is stale, or ""
+// if it exists.
+func (as *adminService) verifyGroup(email string) (string, error) {
+	_, err := as.client.GetGroup(email)
+	if err != nil {
+		if as.checkForAPIErr404(err) {
+			return "group does not exist", nil
+		}
+		return "", err
+	}
+	return "", nil
+}
+
+// verifyUser returns why the user with the given email is stale, or "" if
+// it exists and is not suspended. If maybeGroup is set, the address may
+// also be a group, e.g. because it is not yet a member.
+func (as *adminService) verifyUser(email string, maybeGroup bool) (string, error) {
+	user, err := as.client.GetUser(email)
+	if err != nil {
+		if !as.checkForAPIErr404(err) {
+			return "", err
+		}
+		if !maybeGroup {
+			return "user does not exist", nil
+		}
+		reason, err := as.verifyGroup(email)
+		if reason != "" {
+			reason = "no user or group with this address"
+		}
+		return reason, err
+	}
+	if user.Suspended {
+		if user.SuspensionReason != "" {
+			return fmt.Sprintf("user is suspended (%s)", user.SuspensionReason), nil
+		}
+		return "user is suspended", nil
+	}
+	return "", nil
+}
+
+// inDomain reports whether email belongs to the domain of the tenant, or of
+// group if the tenant has no domain.
+func inDomain(email string, group GoogleGroup) bool {
+	domain := config.Domain
+	if domain == "" {
+		_, domain, _ = strings.Cut(group.EmailId, "@")
+	}
+	_, emailDomain, ok := strings.Cut(email, "@")
+	return ok && domain != "" && strings.EqualFold(emailDomain, domain)
+}
+
+// VerifyMembers checks the owners, managers and members of groups, see
+// AdminService.VerifyGroupMembers, and prints a report of the stale ones
+// to clean up from groups.yaml to w. An error is returned if any was found.
+func (r *Reconciler) VerifyMembers(w io.Writer, groups []GoogleGroup) error {
+	var (
+		stale []StaleMember
+		errs  []error
+	)
+	for _, g := range groups {
+		s, err := r.adminService.VerifyGroupMembers(g)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		stale = append(stale, s...)
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].String() < stale[j].String()
+	})
+
+	for _, s := range stale {
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	log.Printf("verified %d groups, found %d stale members", len(groups), len(stale))
+	if len(stale) > 0 {
+		errs = append(errs, fmt.Errorf("found %d stale members", len(stale)))
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"k8s.io/k8s.io/groups/fake"
+)
+
+func TestVerifyGroupMembers(t *testing.T) {
+	cases := []struct {
+		desc          string
+		g             GoogleGroup
+		modify        func(*fake.FakeAdminServiceClient)
+		expectedStale []StaleMember
+	}{
+		{
+			desc: "all members exist and are active",
+			g: GoogleGroup{
+				EmailId:  "group1@email.com",
+				Members:  []string{"m1-group1@email.com"},
+				Managers: []string{"m2-group1@email.com"},
+			},
+		},
+		{
+			desc: "member with inactive membership",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"m1-group1@email.com"},
+			},
+			modify: func(c *fake.FakeAdminServiceClient) {
+				c.Members["group1@email.com"]["m1-group1@email.com"].Status = "SUSPENDED"
+			},
+			expectedStale: []StaleMember{
+				{Group: "group1@email.com", Email: "m1-group1@email.com", Reason: "membership status is SUSPENDED"},
+			},
+		},
+		{
+			desc: "suspended user",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"m1-group1@email.com"},
+			},
+			modify: func(c *fake.FakeAdminServiceClient) {
+				c.Users["m1-group1@email.com"].Suspended = true
+			},
+			expectedStale: []StaleMember{
+				{Group: "group1@email.com", Email: "m1-group1@email.com", Reason: "user is suspended"},
+			},
+		},
+		{
+			desc: "deleted user",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"m1-group1@email.com"},
+			},
+			modify: func(c *fake.FakeAdminServiceClient) {
+				delete(c.Users, "m1-group1@email.com")
+			},
+			expectedStale: []StaleMember{
+				{Group: "group1@email.com", Email: "m1-group1@email.com", Reason: "user does not exist"},
+			},
+		},
+		{
+			desc: "address that is not yet a member and is neither a user nor a group",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"m1-group1@email.com", "typo@email.com"},
+			},
+			expectedStale: []StaleMember{
+				{Group: "group1@email.com", Email: "typo@email.com", Reason: "no user or group with this address"},
+			},
+		},
+		{
+			desc: "address that is not yet a member and is a group",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"group2@email.com"},
+			},
+		},
+		{
+			desc: "nested group that was deleted",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"group3@email.com"},
+			},
+			modify: func(c *fake.FakeAdminServiceClient) {
+				c.Members["group1@email.com"]["group3@email.com"] = &admin.Member{Email: "group3@email.com", Role: MemberRole, Type: GroupMemberType}
+			},
+			expectedStale: []StaleMember{
+				{Group: "group1@email.com", Email: "group3@email.com", Reason: "group does not exist"},
+			},
+		},
+		{
+			desc: "addresses outside of the domain are not checked",
+			g: GoogleGroup{
+				EmailId: "group1@email.com",
+				Members: []string{"someone@gmail.com"},
+			},
+		},
+		{
+			desc: "group that does not exist yet",
+			g: GoogleGroup{
+				EmailId: "group3@email.com",
+				Members: []string{"m1-group1@email.com", "typo@email.com"},
+			},
+			expectedStale: []StaleMember{
+				{Group: "group3@email.com", Email: "typo@email.com", Reason: "no user or group with this address"},
+			},
+		},
+	}
+
+	errFunc := func(err error) bool {
+		return err != nil
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			fakeClient := fake.NewAugmentedFakeAdminServiceClient()
+			if c.modify != nil {
+				c.modify(fakeClient)
+			}
+			adminSvc, err := NewAdminServiceWithClientAndErrFunc(fakeClient, errFunc)
+			if err != nil {
+				t.Fatalf("error creating client %v", err)
+			}
+
+			stale, err := adminSvc.VerifyGroupMembers(c.g)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(c.expectedStale, stale) {
+				t.Errorf("expected stale members %v, got %v", c.expectedStale, stale)
+			}
+		})
+	}
+}
+
+func TestVerifyMembersEndToEnd(t *testing.T) {
+	server := fake.NewAugmentedFakeServer()
+	defer server.Close()
+	server.Users["m1-group2@email.com"].Suspended = true
+	server.Users["m1-group2@email.com"].SuspensionReason = "ADMIN"
+	server.Members["group1@email.com"]["m2-group1@email.com"].Status = "SUSPENDED"
+
+	reconciler, err := NewReconciler(context.Background(), 1, server.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("error creating reconciler: %v", err)
+	}
+
+	groups := []GoogleGroup{
+		{
+			EmailId:  "group1@email.com",
+			Members:  []string{"m1-group1@email.com", "group2@email.com"},
+			Managers: []string{"m2-group1@email.com"},
+		},
+		{
+			EmailId: "group2@email.com",
+			Members: []string{"m1-group2@email.com", "gone@email.com"},
+			Owners:  []string{"m2-group2@email.com"},
+		},
+	}
+	expectedStale := []StaleMember{
+		{Group: "group1@email.com", Email: "m2-group1@email.com", Reason: "membership status is SUSPENDED"},
+		{Group: "group2@email.com", Email: "m1-group2@email.com", Reason: "user is suspended (ADMIN)"},
+		{Group: "group2@email.com", Email: "gone@email.com", Reason: "no user or group with this address"},
+	}
+
+	var stale []StaleMember
+	for _, g := range groups {
+		s, err := reconciler.adminService.VerifyGroupMembers(g)
+		if err != nil {
+			t.Errorf("unexpected error verifying %s: %v", g.EmailId, err)
+		}
+		stale = append(stale, s...)
+	}
+	if !reflect.DeepEqual(expectedStale, stale) {
+		t.Errorf("expected stale members %v, got %v", expectedStale, stale)
+	}
+
+	var out strings.Builder
+	if err := reconciler.VerifyMembers(&out, groups); err == nil {
+		t.Errorf("expected an error for stale members")
+	}
+	expectedReport := `group1@email.com: m2-group1@email.com: membership status is SUSPENDED
+group2@email.com: gone@email.com: no user or group with this address
+group2@email.com: m1-group2@email.com: user is suspended (ADMIN)
+`
+	if out.String() != expectedReport {
+		t.Errorf("expected report:\n%s\ngot:\n%s", expectedReport, out.String())
+	}
+	out.Reset()
+	if err := reconciler.VerifyMembers(&out, nil); err != nil {
+		t.Errorf("unexpected error without groups: %v", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("expected an empty report without groups, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// githubNoreplyDomain is the domain of the addresses GitHub assigns to its
+// users, of the form <handle>@ or <id>+<handle>@.
+const githubNoreplyDomain = "users.noreply.github.com"
+
+// Membership is a role a person holds in a group.
+type Membership struct {
+	// Group is the email of the group.
+	Group string
+	// Role is the role of Email in Group.
+	Role string
+	// Email is the address listed in Group, as spelled in its groups.yaml
+	// file. It is the last group of Via for transitive memberships.
+	Email string
+	// Pos is where Email is listed.
+	Pos Position
+	// Via are the nested groups through which the person is a member of
+	// Group, outermost last, or empty if the person is listed in Group.
+	Via []string
+}
+
+// personMatcher returns a function reporting whether an address belongs to
+// the person identified by query: an email address, matched like the
+// reconciler does with EmailAddressEquals, or else a GitHub handle. A handle
+// matches GitHub noreply addresses, and the address of the handle at each
+// of domains. It doesn't match other domains, where the same local part may
+// well be someone else.
+func personMatcher(query string, domains []string) func(email string) bool {
+	if strings.Contains(query, "@") && !strings.HasPrefix(query, "@") {
+		return func(email string) bool {
+			return EmailAddressEquals(query, email)
+		}
+	}
+	handle := strings.ToLower(strings.TrimPrefix(query, "@"))
+	return func(email string) bool {
+		local, domain, ok := strings.Cut(strings.ToLower(email), "@")
+		if !ok {
+			return false
+		}
+		if domain == githubNoreplyDomain {
+			if _, h, ok := strings.Cut(local, "+"); ok {
+				local = h
+			}
+			return local == handle
+		}
+		for _, d := range domains {
+			if EmailAddressEquals(handle+"@"+d, email) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// findMemberships returns the memberships in groups of the person whose
+// addresses match, directly and transitively through nested groups. A
+// group listing a group the person is a member of makes the person a member
+// of that group too, with the role the nested group has in it. Each
+// transitive membership is reported once, through the fewest nested groups.
+func findMemberships(groups []GoogleGroup, match func(email string) bool) []Membership {
+	var res []Membership
+	// reached are the groups the person is known to be a member of, and
+	// the nested groups through which they are.
+	reached := map[string][]string{}
+	var frontier []string
+	visit := func(match func(string) bool, via func(string) []string) {
+		for _, g := range groups {
+			for _, list := range []struct {
+				role    string
+				members []string
+			}{
+				{OwnerRole, g.Owners},
+				{ManagerRole, g.Managers},
+				{MemberRole, g.Members},
+			} {
+				for i, m := range list.members {
+					if !match(m) {
+						continue
+					}
+					res = append(res, Membership{Group: g.EmailId, Role: list.role, Email: m, Pos: g.MemberPosition(list.role, i), Via: via(m)})
+					key := CanonicalEmail(g.EmailId)
+					if _, ok := reached[key]; !ok {
+						reached[key] = append(via(m), g.EmailId)
+						frontier = append(frontier, g.EmailId)
+					}
+				}
+			}
+		}
+	}
+
+	visit(match, func(string) []string { return nil })
+	for len(frontier) > 0 {
+		nested := map[string]bool{}
+		for _, email := range frontier {
+			nested[CanonicalEmail(email)] = true
+		}
+		frontier = nil
+		visit(func(m string) bool {
+			return nested[CanonicalEmail(m)]
+		}, func(m string) []string {
+			return append([]string{}, reached[CanonicalEmail(m)]...)
+		})
+	}
+	return dedupeMemberships(res)
+}
+
+// dedupeMemberships drops the transitive memberships of a group and role
+// after the first one, which goes through the fewest nested groups.
+func dedupeMemberships(memberships []Membership) []Membership {
+	var res []Membership
+	seen := map[string]bool{}
+	for _, m := range memberships {
+		if len(m.Via) > 0 {
+			key := CanonicalEmail(m.Group) + " " + m.Role
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// printMemberships prints memberships to w, one per line, with the path of
+// the file listing them relative to the working directory.
+func printMemberships(w io.Writer, memberships []Membership) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, m := range memberships {
+		pos := m.Pos
+		pos.Path = relativePath(pos.Path)
+		how := "as " + m.Email
+		if len(m.Via) > 0 {
+			how = "via " + strings.Join(m.Via, " -> ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.Group, m.Role, how, pos)
+	}
+	return tw.Flush()
+}
+
+// runWhois implements the whois command: it prints the groups and roles of
+// the person given by email or GitHub handle, across the groups of every
+// tenant of the config.
+func runWhois(o *options, args []string) error {
+	fs := newFlagSet("whois", o, false)
+	var domains stringList
+	fs.Var(&domains, "domain", "also match a GitHub handle at this domain, e.g. gmail.com, can be repeated")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("whois takes an email or GitHub handle, got %q", fs.Args())
+	}
+	query := fs.Arg(0)
+
+	if err := config.Load(o.configFile, false); err != nil {
+		return err
+	}
+	var (
+		errs  []error
+		found int
+	)
+	for _, t := range config.Tenants {
+		if err := loadTenant(t); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+			continue
+		}
+		memberships := findMemberships(groupsConfig.Groups, personMatcher(query, domains))
+		if len(memberships) == 0 {
+			continue
+		}
+		found += len(memberships)
+		if len(config.Tenants) > 1 {
+			fmt.Printf("# tenant: %s\n", t.Name)
+		}
+		if err := printMemberships(os.Stdout, memberships); err != nil {
+			return err
+		}
+	}
+	if found == 0 && len(errs) == 0 {
+		fmt.Fprintf(os.Stderr, "%s is not in any group\n", query)
+	}
+	return utilerrors.NewAggregate(errs)
+}
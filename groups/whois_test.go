@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPersonMatcher(t *testing.T) {
+	cases := []struct {
+		query    string
+		domains  []string
+		email    string
+		expected bool
+	}{
+		{"jane.doe@gmail.com", nil, "JaneDoe+k8s@googlemail.com", true},
+		{"jane@example.com", nil, "jane@other.com", false},
+		{"janedoe", []string{"example.com"}, "JaneDoe@example.com", true},
+		{"janedoe", nil, "janedoe@example.com", false},
+		{"janedoe", []string{"example.com"}, "janedoe@unrelated.com", false},
+		{"janedoe", []string{"gmail.com"}, "jane.doe+k8s@gmail.com", true},
+		{"@janedoe", nil, "12345+janedoe@users.noreply.github.com", true},
+		{"janedoe", nil, "janedoe@users.noreply.github.com", true},
+		{"janedoe", []string{"example.com"}, "janedoe2@example.com", false},
+	}
+	for _, c := range cases {
+		if actual := personMatcher(c.query, c.domains)(c.email); actual != c.expected {
+			t.Errorf("%q matching %q: expected %v, got %v", c.query, c.email, c.expected, actual)
+		}
+	}
+}
+
+func TestFindMemberships(t *testing.T) {
+	groups := []GoogleGroup{
+		{
+			EmailId: "leads@example.com",
+			Owners:  []string{"Jane@example.com"},
+			source: groupSource{
+				pos:     Position{Path: "/groups/sig-foo/groups.yaml", Line: 2, Column: 5},
+				members: map[string][]Position{OwnerRole: {{Line: 4, Column: 9}}},
+			},
+		},
+		{
+			EmailId:  "sig-foo@example.com",
+			Managers: []string{"leads@example.com"},
+			Members:  []string{"jane@example.com"},
+		},
+		{
+			EmailId: "all@example.com",
+			Members: []string{"sig-foo@example.com", "leads@example.com"},
+		},
+		{
+			// A cycle doesn't lead to infinite recursion.
+			EmailId: "cycle@example.com",
+			Members: []string{"all@example.com", "cycle@example.com"},
+		},
+		{
+			EmailId: "unrelated@example.com",
+			Members: []string{"john@example.com"},
+		},
+	}
+	expected := []Membership{
+		{Group: "leads@example.com", Role: OwnerRole, Email: "Jane@example.com", Pos: Position{Path: "/groups/sig-foo/groups.yaml", Line: 4, Column: 9}},
+		{Group: "sig-foo@example.com", Role: MemberRole, Email: "jane@example.com"},
+		{Group: "sig-foo@example.com", Role: ManagerRole, Email: "leads@example.com", Via: []string{"leads@example.com"}},
+		{Group: "all@example.com", Role: MemberRole, Email: "sig-foo@example.com", Via: []string{"sig-foo@example.com"}},
+		{Group: "cycle@example.com", Role: MemberRole, Email: "all@example.com", Via: []string{"sig-foo@example.com", "all@example.com"}},
+	}
+
+	actual := findMemberships(groups, personMatcher("jane@example.com", nil))
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected memberships:\n%+v\ngot:\n%+v", expected, actual)
+	}
+}